@@ -0,0 +1,258 @@
+package auth // import "go.microcore.dev/framework/auth"
+
+/*
+Package auth verifies JSON Web Tokens and carries their claims in contexts.
+
+A Verifier checks the signature of tokens with HMAC secrets, public keys or
+JSON Web Key Sets loaded from a file, a URL or an OpenID Connect provider,
+then their expiry, issuer and audience:
+
+	verifier := auth.NewVerifier(
+	    auth.WithOIDCIssuer("https://id.example.com/realms/main"),
+	    auth.WithAudience("orders"),
+	    auth.WithRolesClaim("realm_access.roles"),
+	)
+
+	claims, err := verifier.Verify(ctx, token)
+
+Errors caused by the token wrap transport.ErrUnauthorized.
+*/
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/transport"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type (
+	// Verifier verifies JSON Web Tokens. It is safe for concurrent use.
+	Verifier struct {
+		keys            []keySet
+		issuer          string
+		audiences       []string
+		clockSkew       time.Duration
+		algorithms      []string
+		rolesClaim      string
+		refreshInterval time.Duration
+	}
+
+	// Claims are the claims of a verified token.
+	Claims struct {
+		Subject   string
+		Issuer    string
+		Audience  []string
+		ExpiresAt time.Time
+		IssuedAt  time.Time
+		ID        string
+		// Roles are read from the roles claim, see WithRolesClaim.
+		Roles []string
+		// Scopes are read from the scope (space separated) or scp claim.
+		Scopes []string
+		// Raw holds all claims.
+		Raw map[string]any
+	}
+
+	// keySet returns the verification key of a token.
+	keySet interface {
+		key(ctx context.Context, kid, alg string) (any, error)
+	}
+
+	staticKeySet struct {
+		value any
+	}
+
+	// keySetError is an error loading a key set, which is not caused by
+	// the token.
+	keySetError struct {
+		err error
+	}
+
+	claimsContextKey struct{}
+)
+
+var logger = log.New(pkg)
+
+func NewVerifier(opts ...Option) *Verifier {
+	v := &Verifier{
+		clockSkew:       DefaultClockSkew,
+		algorithms:      defaultAlgorithms,
+		rolesClaim:      DefaultRolesClaim,
+		refreshInterval: DefaultJwksRefreshInterval,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Verify verifies token and returns its claims.
+//
+// It returns an error wrapping transport.ErrUnauthorized if the token is
+// invalid, or another error if its key set cannot be loaded.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(v.algorithms),
+		jwt.WithLeeway(v.clockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+
+	raw := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, raw, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid, t.Method.Alg())
+	}, opts...)
+	if err != nil {
+		var ksErr *keySetError
+		if errors.As(err, &ksErr) {
+			return nil, ksErr.err
+		}
+		return nil, unauthorized(err.Error())
+	}
+
+	claims := newClaims(raw, v.rolesClaim)
+	if len(v.audiences) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.audiences, aud)
+	}) {
+		return nil, unauthorized("token has invalid audience")
+	}
+
+	return claims, nil
+}
+
+// key returns the first key of the key sets matching kid and alg.
+//
+// Load errors are only returned if no key set could be loaded, otherwise
+// the token is not signed by any available key.
+func (v *Verifier) key(ctx context.Context, kid, alg string) (any, error) {
+	var errs, loadErrs []error
+	for _, ks := range v.keys {
+		key, err := ks.key(ctx, kid, alg)
+		if err == nil {
+			return key, nil
+		}
+		var ksErr *keySetError
+		if errors.As(err, &ksErr) {
+			loadErrs = append(loadErrs, ksErr.err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(loadErrs) > 0 {
+		return nil, &keySetError{err: errors.Join(loadErrs...)}
+	}
+	return nil, errors.New("no verification key configured")
+}
+
+// key returns the static key if it can verify alg.
+func (s staticKeySet) key(_ context.Context, _, alg string) (any, error) {
+	if !keyMatchesAlg(s.value, alg) {
+		return nil, errors.New("no key for algorithm " + alg)
+	}
+	return s.value, nil
+}
+
+func (e *keySetError) Error() string {
+	return e.err.Error()
+}
+
+func (e *keySetError) Unwrap() error {
+	return e.err
+}
+
+func unauthorized(message string) error {
+	return transport.NewError(transport.ErrUnauthorized, message, "INVALID_TOKEN")
+}
+
+func newClaims(raw jwt.MapClaims, rolesClaim string) *Claims {
+	claims := &Claims{Raw: raw}
+	claims.Subject, _ = raw.GetSubject()
+	claims.Issuer, _ = raw.GetIssuer()
+	claims.Audience, _ = raw.GetAudience()
+	if exp, _ := raw.GetExpirationTime(); exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+	if iat, _ := raw.GetIssuedAt(); iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+	claims.ID, _ = raw["jti"].(string)
+
+	claims.Roles = stringsClaim(lookupClaim(raw, rolesClaim))
+	claims.Scopes = stringsClaim(raw["scope"])
+	if len(claims.Scopes) == 0 {
+		claims.Scopes = stringsClaim(raw["scp"])
+	}
+
+	return claims
+}
+
+// lookupClaim returns the claim at the dot separated path.
+func lookupClaim(raw map[string]any, path string) any {
+	var value any = raw
+	for name := range strings.SplitSeq(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[name]
+	}
+	return value
+}
+
+// stringsClaim returns the strings of an array or space separated claim.
+func stringsClaim(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		values := []string{}
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// HasRole reports whether the subject has one of roles.
+func (c *Claims) HasRole(roles ...string) bool {
+	return slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(c.Roles, role)
+	})
+}
+
+// HasScopes reports whether the token grants all scopes.
+func (c *Claims) HasScopes(scopes ...string) bool {
+	return !slices.ContainsFunc(scopes, func(scope string) bool {
+		return !slices.Contains(c.Scopes, scope)
+	})
+}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// FromContext returns the claims carried by ctx, see NewContext.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
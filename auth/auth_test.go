@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"go.microcore.dev/framework/transport"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":          "user-1",
+		"iss":          "https://id.example.com",
+		"aud":          []string{"orders", "billing"},
+		"exp":          time.Now().Add(time.Minute).Unix(),
+		"iat":          time.Now().Unix(),
+		"scope":        "orders:read orders:write",
+		"realm_access": map[string]any{"roles": []string{"admin"}},
+	}
+}
+
+func TestVerifyHmac(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("secret")
+	v := NewVerifier(
+		WithHmacSecret(secret),
+		WithIssuer("https://id.example.com"),
+		WithAudience("orders"),
+		WithRolesClaim("realm_access.roles"),
+	)
+
+	claims, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", secret, validClaims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" || !claims.HasRole("user", "admin") || !claims.HasScopes("orders:read", "orders:write") || claims.HasScopes("orders:delete") {
+		t.Fatalf("got %+v", claims)
+	}
+
+	ctx = NewContext(ctx, claims)
+	if got, ok := FromContext(ctx); !ok || got != claims {
+		t.Fatal("claims not in context")
+	}
+
+	// Expired within the clock skew
+	c := validClaims()
+	c["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", secret, c)); err != nil {
+		t.Fatalf("clock skew: %v", err)
+	}
+
+	invalid := map[string]func(jwt.MapClaims){
+		"expired":  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"no exp":   func(c jwt.MapClaims) { delete(c, "exp") },
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "payments" },
+	}
+	for name, modify := range invalid {
+		c := validClaims()
+		modify(c)
+		if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", secret, c)); !errors.Is(err, transport.ErrUnauthorized) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", []byte("other"), validClaims())); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("signature: got %v", err)
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims())); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("none: got %v", err)
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestVerifyJwks(t *testing.T) {
+	ctx := context.Background()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	keys := []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	write := func() {
+		data, _ := json.Marshal(map[string]any{"keys": keys})
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write()
+
+	v := NewVerifier(WithJwksFile(path))
+	set := v.keys[0].(*jwks)
+	now := time.Now()
+	set.now = func() time.Time { return now }
+
+	for _, tt := range []struct {
+		method jwt.SigningMethod
+		kid    string
+		key    any
+	}{
+		{jwt.SigningMethodRS256, "rsa", rsaKey},
+		{jwt.SigningMethodPS384, "rsa", rsaKey},
+		{jwt.SigningMethodES256, "ec", ecKey},
+	} {
+		if _, err := v.Verify(ctx, sign(t, tt.method, tt.kid, tt.key, validClaims())); err != nil {
+			t.Errorf("%s: %v", tt.method.Alg(), err)
+		}
+	}
+
+	// Key confusion: an HMAC token signed with the RSA modulus
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "rsa", rsaKey.N.Bytes(), validClaims())); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("HS256 with rsa key: got %v", err)
+	}
+
+	// Rotated key, reloaded after DefaultJwksMinRefreshInterval
+	keys = append(keys, map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)})
+	write()
+	token := sign(t, jwt.SigningMethodEdDSA, "ed", edKey, validClaims())
+	if _, err := v.Verify(ctx, token); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("rotated before refresh: got %v", err)
+	}
+	now = now.Add(DefaultJwksMinRefreshInterval)
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Errorf("rotated after refresh: %v", err)
+	}
+
+	// Previous keys are kept if the set cannot be reloaded
+	os.Remove(path)
+	now = now.Add(DefaultJwksRefreshInterval)
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Errorf("stale keys: %v", err)
+	}
+
+	// Load errors are not token errors, until the set loads
+	v = NewVerifier(WithJwksFile(path))
+	set = v.keys[0].(*jwks)
+	set.now = func() time.Time { return now }
+	for _, token := range []string{token, sign(t, jwt.SigningMethodES256, "", ecKey, validClaims())} {
+		for i := range 2 {
+			_, err := v.Verify(ctx, token)
+			if err == nil || errors.Is(err, transport.ErrUnauthorized) {
+				t.Errorf("missing jwks, verify %d: got %v", i, err)
+			}
+		}
+	}
+	write()
+	if _, err := v.Verify(ctx, token); err == nil {
+		t.Error("retry before DefaultJwksRetryInterval: got no error")
+	}
+	now = now.Add(DefaultJwksRetryInterval)
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Errorf("retry after DefaultJwksRetryInterval: %v", err)
+	}
+
+	// Load errors of a key set do not hide token errors of the others
+	secret := []byte("secret")
+	v = NewVerifier(WithJwksFile(filepath.Join(t.TempDir(), "missing.json")), WithHmacSecret(secret))
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", secret, validClaims())); err != nil {
+		t.Errorf("hmac with missing jwks: %v", err)
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, "", []byte("other"), validClaims())); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("invalid hmac with missing jwks: got %v", err)
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodES256, "ec", ecKey, validClaims())); !errors.Is(err, transport.ErrUnauthorized) {
+		t.Errorf("ec with missing jwks: got %v", err)
+	}
+
+	if got := stringsClaim([]any{"a", 1, "b"}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("strings claim: got %v", got)
+	}
+}
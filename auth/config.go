@@ -0,0 +1,41 @@
+package auth // import "go.microcore.dev/framework/auth"
+
+import (
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/auth"
+
+	// Tolerated difference between the clocks of the issuer and the
+	// service when checking exp, nbf and iat.
+	DefaultClockSkew = 30 * time.Second
+
+	// Interval of the reload of JSON Web Key Sets.
+	DefaultJwksRefreshInterval = 15 * time.Minute
+	// Minimum interval of the reloads caused by unknown key IDs, which
+	// happen when the issuer rotates its keys.
+	DefaultJwksMinRefreshInterval = time.Minute
+	// Minimum interval of the reloads following a failed load.
+	DefaultJwksRetryInterval = 5 * time.Second
+	// Maximum duration of the load of a JSON Web Key Set.
+	DefaultJwksLoadTimeout = 10 * time.Second
+
+	DefaultRolesClaim = "roles"
+
+	// Path of the OpenID Connect discovery document, relative to the issuer.
+	OIDCDiscoveryPath = "/.well-known/openid-configuration"
+)
+
+var (
+	// Signing algorithms accepted by default. "none" is never accepted.
+	defaultAlgorithms = []string{
+		"HS256", "HS384", "HS512",
+		"RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512",
+		"EdDSA",
+	}
+)
@@ -0,0 +1,340 @@
+package auth // import "go.microcore.dev/framework/auth"
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/transport/http/client"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type (
+	// jwks is a JSON Web Key Set reloaded periodically and on unknown key
+	// IDs.
+	jwks struct {
+		load     func(ctx context.Context) ([]byte, error)
+		verifier *Verifier
+		group    singleflight.Group
+
+		mu      sync.Mutex
+		keys    []jwk
+		fetched time.Time
+		failed  time.Time
+		err     error
+		now     func() time.Time
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		K   string `json:"k"`
+
+		key any
+	}
+)
+
+func newJwks(load func(ctx context.Context) ([]byte, error), verifier *Verifier) *jwks {
+	return &jwks{
+		load:     load,
+		verifier: verifier,
+		now:      time.Now,
+	}
+}
+
+func (s *jwks) key(ctx context.Context, kid, alg string) (any, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	fetched := s.fetched
+	s.mu.Unlock()
+
+	if fetched.IsZero() || now.Sub(fetched) >= s.verifier.refreshInterval {
+		// Keep verifying with the previous keys on error
+		if err := s.refresh(ctx); err != nil && fetched.IsZero() {
+			return nil, err
+		}
+	}
+
+	if key, ok := s.find(kid, alg); ok {
+		return key, nil
+	}
+
+	// The issuer may have rotated its keys
+	s.mu.Lock()
+	fetched = s.fetched
+	s.mu.Unlock()
+
+	if kid != "" && now.Sub(fetched) >= DefaultJwksMinRefreshInterval && s.refresh(ctx) == nil {
+		if key, ok := s.find(kid, alg); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no key %q for algorithm %s", kid, alg)
+}
+
+// refresh reloads the keys, unless the last load failed less than
+// DefaultJwksRetryInterval ago. Concurrent calls share a single load, which
+// is not canceled with ctx. The previous keys are kept on error.
+func (s *jwks) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.failed.IsZero() && s.now().Sub(s.failed) < DefaultJwksRetryInterval {
+		err := s.err
+		s.mu.Unlock()
+		return err
+	}
+	s.mu.Unlock()
+
+	_, err, _ := s.group.Do("", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultJwksLoadTimeout)
+		defer cancel()
+
+		keys, err := s.fetch(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err != nil {
+			s.failed = s.now()
+			s.err = err
+			if s.keys != nil {
+				logger.WarnContext(ctx, "jwks refresh", log.Err(err))
+			}
+			return nil, err
+		}
+		s.keys = keys
+		s.fetched = s.now()
+		s.failed = time.Time{}
+		s.err = nil
+		return nil, nil
+	})
+
+	return err
+}
+
+func (s *jwks) fetch(ctx context.Context) ([]jwk, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, &keySetError{err: fmt.Errorf("load jwks: %w", err)}
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, &keySetError{err: fmt.Errorf("parse jwks: %w", err)}
+	}
+
+	keys := []jwk{}
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"jwk skipped",
+				slog.String("kid", k.Kid),
+				log.Err(err),
+			)
+			continue
+		}
+		k.key = key
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// find returns the key with kid, or the only key if kid is empty, if it
+// can verify alg.
+func (s *jwks) find(kid, alg string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if kid != "" && k.Kid != kid || kid == "" && len(s.keys) > 1 {
+			continue
+		}
+		if k.Alg != "" && k.Alg != alg || !keyMatchesAlg(k.key, alg) {
+			continue
+		}
+		return k.key, true
+	}
+	return nil, false
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) > 4 {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var (
+			curve elliptic.Curve
+			ec    ecdh.Curve
+		)
+		switch k.Crv {
+		case "P-256":
+			curve, ec = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ec = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ec = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid ec coordinates")
+		}
+		// Check the point is on the curve
+		if _, err := ec.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return decodeBase64(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// keyMatchesAlg reports whether key can verify the algorithm alg.
+func keyMatchesAlg(key any, alg string) bool {
+	switch key.(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func fileLoader(path string) func(ctx context.Context) ([]byte, error) {
+	return func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+func urlLoader(url string) func(ctx context.Context) ([]byte, error) {
+	c := client.New()
+	return func(ctx context.Context) ([]byte, error) {
+		return get(ctx, c, url)
+	}
+}
+
+// oidcLoader loads the key set of the jwks_uri of the discovery document of
+// issuer, fetched once.
+func oidcLoader(issuer string) func(ctx context.Context) ([]byte, error) {
+	var (
+		c       = client.New()
+		mu      sync.Mutex
+		jwksURI string
+	)
+	return func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if jwksURI == "" {
+			data, err := get(ctx, c, strings.TrimSuffix(issuer, "/")+OIDCDiscoveryPath)
+			if err != nil {
+				return nil, err
+			}
+			var discovery struct {
+				Issuer  string `json:"issuer"`
+				JwksURI string `json:"jwks_uri"`
+			}
+			if err := json.Unmarshal(data, &discovery); err != nil {
+				return nil, fmt.Errorf("parse discovery document: %w", err)
+			}
+			if discovery.Issuer != issuer || discovery.JwksURI == "" {
+				return nil, fmt.Errorf("invalid discovery document of %s", issuer)
+			}
+			jwksURI = discovery.JwksURI
+		}
+
+		return get(ctx, c, jwksURI)
+	}
+}
+
+func get(ctx context.Context, c client.Manager, url string) ([]byte, error) {
+	resp, err := c.Request(url, client.WithRequestContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("get %s: status %d", url, resp.StatusCode())
+	}
+	return resp.Body(), nil
+}
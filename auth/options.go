@@ -0,0 +1,98 @@
+package auth // import "go.microcore.dev/framework/auth"
+
+import (
+	"crypto"
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+type Option func(*Verifier)
+
+// WithHmacSecret verifies HS256, HS384 and HS512 tokens with secret.
+func WithHmacSecret(secret []byte) Option {
+	return func(v *Verifier) {
+		v.keys = append(v.keys, staticKeySet{value: secret})
+	}
+}
+
+// WithPublicKey verifies tokens with key, an *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey.
+func WithPublicKey(key crypto.PublicKey) Option {
+	return func(v *Verifier) {
+		v.keys = append(v.keys, staticKeySet{value: key})
+	}
+}
+
+// WithJwksURL verifies tokens with the JSON Web Key Set served at url. It
+// is reloaded every DefaultJwksRefreshInterval, and when a token is signed
+// with an unknown key ID, at most every DefaultJwksMinRefreshInterval.
+func WithJwksURL(url string) Option {
+	return func(v *Verifier) {
+		v.keys = append(v.keys, newJwks(urlLoader(url), v))
+	}
+}
+
+// WithJwksFile verifies tokens with the JSON Web Key Set of the file at
+// path, reloaded like WithJwksURL.
+func WithJwksFile(path string) Option {
+	return func(v *Verifier) {
+		v.keys = append(v.keys, newJwks(fileLoader(path), v))
+	}
+}
+
+// WithOIDCIssuer verifies tokens issued by the OpenID Connect provider
+// issuer: the iss claim must be issuer, and keys are loaded from the
+// jwks_uri of its discovery document, fetched on first use.
+func WithOIDCIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+		v.keys = append(v.keys, newJwks(oidcLoader(issuer), v))
+	}
+}
+
+// WithIssuer requires the iss claim to be issuer.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain one of audiences.
+func WithAudience(audiences ...string) Option {
+	return func(v *Verifier) {
+		v.audiences = audiences
+	}
+}
+
+// WithClockSkew sets the tolerated clock skew. Defaults to
+// DefaultClockSkew.
+func WithClockSkew(skew time.Duration) Option {
+	return func(v *Verifier) {
+		v.clockSkew = skew
+	}
+}
+
+// WithAlgorithms restricts the accepted signing algorithms, e.g. RS256.
+func WithAlgorithms(algorithms ...string) Option {
+	return func(v *Verifier) {
+		v.algorithms = algorithms
+	}
+}
+
+// WithRolesClaim sets the claim holding the roles of the subject, as an
+// array or a space separated string. Nested claims are separated by dots,
+// e.g. realm_access.roles. Defaults to DefaultRolesClaim.
+func WithRolesClaim(claim string) Option {
+	return func(v *Verifier) {
+		v.rolesClaim = claim
+	}
+}
+
+// WithJwksRefreshInterval sets the reload interval of JSON Web Key Sets.
+// Defaults to DefaultJwksRefreshInterval.
+func WithJwksRefreshInterval(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.refreshInterval = interval
+	}
+}
@@ -47,7 +47,7 @@ func BoolDefault(key string, def bool) bool {
 	if err != nil {
 		logger.Warn(
 			"failed to parse bool value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Bool("default", def),
 		)
@@ -83,7 +83,7 @@ func IntDefault(key string, def int) int {
 	if err != nil {
 		logger.Warn(
 			"failed to parse int value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Int("default", def),
 		)
@@ -119,7 +119,7 @@ func Int64Default(key string, def int64) int64 {
 	if err != nil {
 		slog.Warn(
 			"failed to parse int64 value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Int64("default", def),
 		)
@@ -175,7 +175,7 @@ func DurDefault(key string, def time.Duration) time.Duration {
 	if err != nil {
 		logger.Warn(
 			"failed to parse duration value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Duration("default", def),
 		)
@@ -211,7 +211,7 @@ func BytesHexDefault(key string, def []byte) []byte {
 	if err != nil {
 		logger.Warn(
 			"failed to parse hex value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Any("default", def),
 		)
@@ -247,7 +247,7 @@ func BytesB64Default(key string, def []byte) []byte {
 	if err != nil {
 		logger.Warn(
 			"failed to parse base64 value, using default",
-			slog.Any("error", err),
+			log.Err(err),
 			slog.String("key", key),
 			slog.Any("default", def),
 		)
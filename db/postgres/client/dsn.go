@@ -3,25 +3,12 @@ package client // import "go.microcore.dev/framework/db/postgres/client"
 import (
 	"net/url"
 	"regexp"
+
+	"go.microcore.dev/framework/log"
 )
 
 // List of sensitive parameter keys whose values should be masked in logs.
-var sensitiveKeys = []string{
-	// User authentication
-	"user", "username", "password", "passfile", "require_auth",
-
-	// SSL/TLS
-	"sslkey", "sslpassword", "sslcert", "sslkeylogfile", "sslrootcert", "sslcrl",
-
-	// OAuth
-	"oauth_client_id", "oauth_client_secret",
-
-	// SCRAM
-	"scram_client_key", "scram_server_key",
-
-	// Kerberos / GSSAPI
-	"krbsrvname", "gsslib", "gssdelegation",
-}
+var sensitiveKeys = append([]string{"user", "username"}, log.CredentialKeys...)
 
 // Mask placeholder for sensitive values.
 const mask = "xxxxx"
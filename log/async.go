@@ -0,0 +1,299 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+type (
+	// AsyncOptions configures AsyncHandler.
+	AsyncOptions struct {
+		// BufferSize is the capacity of the queue.
+		// Defaults to DefaultAsyncBufferSize.
+		BufferSize int
+
+		// Policy decides what happens when the queue is full.
+		Policy AsyncPolicy
+	}
+
+	AsyncPolicy int
+
+	// AsyncHandler queues records and writes them to the wrapped handler
+	// from a background goroutine, so slow outputs do not stall callers.
+	//
+	// Queued records are flushed by Flush and Close. After Close, records
+	// are written synchronously. See CloseAsync.
+	AsyncHandler struct {
+		handler slog.Handler
+		state   *asyncState
+	}
+
+	asyncState struct {
+		opts  AsyncOptions
+		queue chan asyncEntry
+		// owned is set for the handlers built by NewHandler, closed when
+		// they are no longer outputs of the global logger.
+		owned bool
+
+		mu     sync.RWMutex
+		closed bool
+		done   chan struct{}
+	}
+
+	asyncEntry struct {
+		ctx     context.Context
+		handler slog.Handler
+		record  slog.Record
+		flushed chan struct{}
+	}
+)
+
+const (
+	// AsyncPolicyDrop drops records when the queue is full. Dropped
+	// records are counted in DroppedRecords as DropReasonQueueFull.
+	AsyncPolicyDrop AsyncPolicy = iota
+
+	// AsyncPolicyBlock waits for room in the queue.
+	AsyncPolicyBlock
+)
+
+const DropReasonQueueFull = "queue_full"
+
+var (
+	asyncMu       sync.Mutex
+	asyncHandlers []*asyncState
+)
+
+// NewAsyncHandler starts a goroutine that writes queued records to handler.
+// It runs until Close is called.
+func NewAsyncHandler(handler slog.Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultAsyncBufferSize
+	}
+
+	state := &asyncState{
+		opts:  opts,
+		queue: make(chan asyncEntry, opts.BufferSize),
+		done:  make(chan struct{}),
+	}
+	go state.run()
+
+	asyncMu.Lock()
+	asyncHandlers = append(asyncHandlers, state)
+	asyncMu.Unlock()
+
+	return &AsyncHandler{
+		handler: handler,
+		state:   state,
+	}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+
+	if h.state.closed {
+		return h.handler.Handle(ctx, r)
+	}
+
+	entry := asyncEntry{
+		ctx:     context.WithoutCancel(ctx),
+		handler: h.handler,
+		record:  r.Clone(),
+	}
+
+	if h.state.opts.Policy == AsyncPolicyBlock {
+		h.state.queue <- entry
+		return nil
+	}
+
+	select {
+	case h.state.queue <- entry:
+	default:
+		addDroppedRecords(DropReasonQueueFull, 1)
+	}
+	return nil
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithAttrs(attrs), state: h.state}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithGroup(name), state: h.state}
+}
+
+// Len returns the number of queued records.
+func (h *AsyncHandler) Len() int {
+	return len(h.state.queue)
+}
+
+// Flush waits until all records queued before the call are written.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	return h.state.flush(ctx)
+}
+
+// Close flushes the queue and stops the background goroutine.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	err := h.state.flush(ctx)
+
+	h.state.mu.Lock()
+	if !h.state.closed {
+		h.state.closed = true
+		close(h.state.queue)
+	}
+	h.state.mu.Unlock()
+
+	asyncMu.Lock()
+	asyncHandlers = slices.DeleteFunc(asyncHandlers, func(s *asyncState) bool { return s == h.state })
+	asyncMu.Unlock()
+
+	select {
+	case <-h.state.done:
+	case <-ctx.Done():
+		err = errors.Join(err, ctx.Err())
+	}
+	return err
+}
+
+func (s *asyncState) run() {
+	defer close(s.done)
+	for entry := range s.queue {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		_ = entry.handler.Handle(entry.ctx, entry.record)
+	}
+}
+
+func (s *asyncState) flush(ctx context.Context) error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case s.queue <- asyncEntry{flushed: flushed}:
+		s.mu.RUnlock()
+	case <-ctx.Done():
+		s.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush waits until every AsyncHandler has written its queued records.
+func Flush(ctx context.Context) error {
+	asyncMu.Lock()
+	states := slices.Clone(asyncHandlers)
+	asyncMu.Unlock()
+
+	var errs []error
+	for _, s := range states {
+		if err := s.flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAsync closes every AsyncHandler, so that their queued records are
+// written and later records are written synchronously. A shutdown handler
+// of the shutdown package calls it before the process exits.
+func CloseAsync(ctx context.Context) error {
+	asyncMu.Lock()
+	states := slices.Clone(asyncHandlers)
+	asyncMu.Unlock()
+
+	var errs []error
+	for _, s := range states {
+		if err := (&AsyncHandler{state: s}).Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// replacedHandlers returns the handlers built by NewHandler that are no
+// longer outputs of the global logger. They are closed with closeHandlers
+// once mu is released, as closing waits for their queues. The caller must
+// hold mu.
+func replacedHandlers(handlers ...slog.Handler) []slog.Handler {
+	var replaced []slog.Handler
+	for _, h := range handlers {
+		if h == primary || slices.ContainsFunc(sinks, func(s sink) bool { return s.owner == h }) {
+			continue
+		}
+		if sampling, async := owned(h); sampling != nil || async != nil {
+			replaced = append(replaced, h)
+		}
+	}
+	return replaced
+}
+
+// owned returns the SamplingHandler and AsyncHandler of a handler built by
+// NewHandler, or nil for those it does not have.
+func owned(h slog.Handler) (sampling *SamplingHandler, async *AsyncHandler) {
+	for {
+		switch t := h.(type) {
+		case *TraceHandler:
+			h = t.handler
+		case *SamplingHandler:
+			if t.state.owned {
+				sampling = t
+			}
+			h = t.handler
+		case *AsyncHandler:
+			if t.state.owned {
+				async = t
+			}
+			return sampling, async
+		default:
+			return sampling, async
+		}
+	}
+}
+
+// closeHandlers closes handlers built by NewHandler. Sampling is closed
+// first, so that its last summary is queued, then the AsyncHandler, waiting
+// at most DefaultAsyncCloseTimeout. The caller must not hold mu.
+func closeHandlers(handlers []slog.Handler) {
+	for _, h := range handlers {
+		sampling, async := owned(h)
+		if sampling != nil {
+			sampling.Close()
+		}
+		if async != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), DefaultAsyncCloseTimeout)
+			_ = async.Close(ctx)
+			cancel()
+		}
+	}
+}
+
+// QueueLen returns the number of records queued in all AsyncHandlers.
+// Telemetry exports it as the log.queue.depth metric.
+func QueueLen() int {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+	var n int
+	for _, s := range asyncHandlers {
+		n += len(s.queue)
+	}
+	return n
+}
@@ -12,13 +12,35 @@ import (
 const (
 	DefaultLogLevel = slog.LevelInfo
 	DefaultFormat   = FormatPretty
+
+	// EnvPackageLevels holds per-package level overrides applied by
+	// SetDefaultState, e.g. "go.microcore.dev/framework/transport/kafka=debug".
+	EnvPackageLevels = "LOG_LEVELS"
+
+	// EnvGCPProjectID is the default project of FormatGCP.
+	EnvGCPProjectID = "GOOGLE_CLOUD_PROJECT"
+
+	DefaultSamplingInterval       = time.Second
+	DefaultSamplingKeepLevel      = slog.LevelError
+	DefaultSamplingReportInterval = time.Minute
+
+	DefaultRedactMask = "[REDACTED]"
+
+	DefaultRotatePerm = 0o644
+
+	DefaultAsyncBufferSize = 4096
+	// Maximum duration of the flush of an AsyncHandler replaced by
+	// SetBackend, AddSink or RemoveSink.
+	DefaultAsyncCloseTimeout = 5 * time.Second
+
+	DefaultCrashBufferSize = 1000
 )
 
 var (
 	DefaultWriter            = os.Stderr
 	DefaultTimeFormat        = time.StampMilli
 	DefaultPrettyReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
-		if a.Key == "pkg" {
+		if a.Key == PackageKey {
 			return tint.Attr(243, a)
 		}
 		if a.Key == "status" {
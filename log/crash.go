@@ -0,0 +1,229 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// CrashBufferOptions configures the crash buffer, see EnableCrashBuffer.
+	CrashBufferOptions struct {
+		// Size is the number of records retained.
+		// Defaults to DefaultCrashBufferSize.
+		Size int
+
+		// Level is the minimum level retained, independent of the global
+		// level. If nil, records of all levels are retained.
+		Level slog.Leveler
+
+		// Writer receives the dump. Defaults to os.Stderr.
+		Writer io.Writer
+
+		// Filename, if set, is appended to instead of Writer.
+		Filename string
+	}
+
+	// RingHandler is a slog.Handler that retains the last records in
+	// memory, dropping the oldest ones when full.
+	RingHandler struct {
+		ring   *ring
+		attrs  []slog.Attr
+		groups []string
+	}
+
+	ring struct {
+		mu      sync.Mutex
+		records []slog.Record
+		next    int
+		full    bool
+	}
+
+	crashBuffer struct {
+		opts    CrashBufferOptions
+		ring    *RingHandler
+		handler slog.Handler
+	}
+)
+
+var crash atomic.Pointer[crashBuffer]
+
+func NewRingHandler(size int) *RingHandler {
+	if size <= 0 {
+		size = DefaultCrashBufferSize
+	}
+	return &RingHandler{
+		ring: &ring{records: make([]slog.Record, size)},
+	}
+}
+
+func (h *RingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *RingHandler) Handle(_ context.Context, r slog.Record) error {
+	if len(h.attrs) > 0 || len(h.groups) > 0 {
+		attrs := []slog.Attr{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		})
+		for i := len(h.groups) - 1; i >= 0; i-- {
+			attrs = []slog.Attr{slog.Attr{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+		}
+		r = slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r.AddAttrs(h.attrs...)
+		r.AddAttrs(attrs...)
+	} else {
+		r = r.Clone()
+	}
+
+	h.ring.mu.Lock()
+	defer h.ring.mu.Unlock()
+	h.ring.records[h.ring.next] = r
+	h.ring.next = (h.ring.next + 1) % len(h.ring.records)
+	if h.ring.next == 0 {
+		h.ring.full = true
+	}
+	return nil
+}
+
+func (h *RingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{slog.Attr{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return &RingHandler{
+		ring:   h.ring,
+		attrs:  append(slices.Clone(h.attrs), attrs...),
+		groups: h.groups,
+	}
+}
+
+func (h *RingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &RingHandler{
+		ring:   h.ring,
+		attrs:  h.attrs,
+		groups: append(slices.Clone(h.groups), name),
+	}
+}
+
+// Records returns the retained records, oldest first.
+func (h *RingHandler) Records() []slog.Record {
+	h.ring.mu.Lock()
+	defer h.ring.mu.Unlock()
+	if !h.ring.full {
+		return slices.Clone(h.ring.records[:h.ring.next])
+	}
+	return append(
+		slices.Clone(h.ring.records[h.ring.next:]),
+		h.ring.records[:h.ring.next]...,
+	)
+}
+
+// Reset discards the retained records.
+func (h *RingHandler) Reset() {
+	h.ring.mu.Lock()
+	defer h.ring.mu.Unlock()
+	clear(h.ring.records)
+	h.ring.next = 0
+	h.ring.full = false
+}
+
+// Dump writes the retained records to w in text format, oldest first,
+// and discards them.
+func (h *RingHandler) Dump(w io.Writer) error {
+	records := h.Records()
+	h.Reset()
+	if len(records) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "--- last %d log records ---\n", len(records)); err != nil {
+		return err
+	}
+	text := slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.Level(math.MinInt)})
+	var errs []error
+	for _, r := range records {
+		if err := text.Handle(context.Background(), r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	_, err := fmt.Fprintln(w, "--- end of log records ---")
+	return errors.Join(append(errs, err)...)
+}
+
+// EnableCrashBuffer retains the last records of the global logger in
+// memory, including records below the global and per-package levels.
+// The shutdown package dumps them when the process exits with a non-zero
+// code, and the HTTP server does when it recovers a panic, at most once per
+// router.DefaultRouterCrashDumpInterval. See DumpCrashBuffer.
+//
+// Retaining records below the configured level means they are built even
+// if no output writes them, which has a cost on hot paths.
+//
+// Example:
+//
+//	log.EnableCrashBuffer(log.CrashBufferOptions{Size: 500})
+func EnableCrashBuffer(opts CrashBufferOptions) {
+	if opts.Size <= 0 {
+		opts.Size = DefaultCrashBufferSize
+	}
+	if opts.Writer == nil {
+		opts.Writer = os.Stderr
+	}
+	ring := NewRingHandler(opts.Size)
+
+	mu.Lock()
+	defer mu.Unlock()
+	crash.Store(&crashBuffer{
+		opts:    opts,
+		ring:    ring,
+		handler: newCrashHandler(ring),
+	})
+}
+
+// newCrashHandler returns the handler feeding ring, which applies the
+// redaction of SetRedaction. The caller must hold mu.
+func newCrashHandler(ring *RingHandler) slog.Handler {
+	var handler slog.Handler = NewTraceHandler(ring)
+	if redaction != nil {
+		handler = NewRedactHandler(handler, *redaction)
+	}
+	return handler
+}
+
+// DisableCrashBuffer stops retaining records and discards them.
+func DisableCrashBuffer() {
+	crash.Store(nil)
+}
+
+// DumpCrashBuffer writes the records retained by EnableCrashBuffer and
+// discards them. It does nothing if the crash buffer is disabled.
+func DumpCrashBuffer() error {
+	c := crash.Load()
+	if c == nil {
+		return nil
+	}
+	if c.opts.Filename == "" {
+		return c.ring.Dump(c.opts.Writer)
+	}
+	f, err := os.OpenFile(c.opts.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, DefaultRotatePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open crash dump file: %w", err)
+	}
+	return errors.Join(c.ring.Dump(f), f.Close())
+}
+
+func (c *crashBuffer) enabled(level slog.Level) bool {
+	return c.opts.Level == nil || level >= c.opts.Level.Level()
+}
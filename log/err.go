@@ -0,0 +1,195 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// ErrorKey is the attribute key used by Err.
+const ErrorKey = "error"
+
+type (
+	// ErrorCause describes one error of a chain expanded by Err.
+	// It renders as an object in JSON and OpenTelemetry, and as its
+	// message in text formats.
+	ErrorCause map[string]any
+
+	// ErrorChain is the list of causes expanded by Err. Text formats
+	// render it as the messages joined by " <- ".
+	ErrorChain []ErrorCause
+
+	// stackError carries the stack captured by WithStack.
+	stackError struct {
+		err error
+		pcs []uintptr
+	}
+
+	coder interface {
+		GetCode() string
+	}
+
+	baser interface {
+		GetBase() error
+	}
+
+	exitCoder interface {
+		ExitCode() int
+	}
+)
+
+// Err returns an attribute describing err as a group:
+//
+//	message    err.Error()
+//	type       Go type of err
+//	code       code of the first error in the chain with a GetCode method
+//	           (transport.Error)
+//	base       base error of the first error with a GetBase method
+//	exit_code  code of the first error with an ExitCode method
+//	           (shutdown.ExitReason)
+//	chain      wrapped and joined errors, depth first, as ErrorChain
+//	stack      stack captured by WithStack or ErrStack, if any
+//
+// Example:
+//
+//	logger.Error("request failed", log.Err(err))
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Any(ErrorKey, nil)
+	}
+	return slog.Attr{Key: ErrorKey, Value: slog.GroupValue(errorAttrs(err, nil)...)}
+}
+
+// ErrStack works like Err, but also captures the stack of the caller if no
+// error in the chain carries one.
+func ErrStack(err error) slog.Attr {
+	if err == nil {
+		return slog.Any(ErrorKey, nil)
+	}
+	var se *stackError
+	if errors.As(err, &se) {
+		return Err(err)
+	}
+	return slog.Attr{Key: ErrorKey, Value: slog.GroupValue(errorAttrs(err, callers(3))...)}
+}
+
+// WithStack returns err annotated with the stack of the caller, rendered by
+// Err. It returns nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{err: err, pcs: callers(3)}
+}
+
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+func (c ErrorCause) String() string {
+	return fmt.Sprint(c["message"])
+}
+
+func (c ErrorChain) String() string {
+	messages := make([]string, len(c))
+	for i, cause := range c {
+		messages[i] = cause.String()
+	}
+	return strings.Join(messages, " <- ")
+}
+
+func errorAttrs(err error, pcs []uintptr) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("message", err.Error()),
+		slog.String("type", fmt.Sprintf("%T", err)),
+	}
+
+	var c coder
+	if errors.As(err, &c) {
+		attrs = append(attrs, slog.String("code", c.GetCode()))
+	}
+	var b baser
+	if errors.As(err, &b) && b.GetBase() != nil {
+		attrs = append(attrs, slog.String("base", b.GetBase().Error()))
+	}
+	var ec exitCoder
+	if errors.As(err, &ec) {
+		attrs = append(attrs, slog.Int("exit_code", ec.ExitCode()))
+	}
+
+	var chain ErrorChain
+	for _, e := range unwrap(err) {
+		chain = appendChain(chain, e)
+	}
+	if len(chain) > 0 {
+		attrs = append(attrs, slog.Any("chain", chain))
+	}
+
+	var se *stackError
+	if errors.As(err, &se) {
+		pcs = se.pcs
+	}
+	if len(pcs) > 0 {
+		attrs = append(attrs, slog.String("stack", formatStack(pcs)))
+	}
+
+	return attrs
+}
+
+// appendChain appends err and its causes, depth first. Stack annotations
+// are skipped as they do not add a message.
+func appendChain(chain ErrorChain, err error) ErrorChain {
+	if _, ok := err.(*stackError); !ok {
+		cause := ErrorCause{
+			"message": err.Error(),
+			"type":    fmt.Sprintf("%T", err),
+		}
+		if c, ok := err.(coder); ok {
+			cause["code"] = c.GetCode()
+		}
+		if c, ok := err.(exitCoder); ok {
+			cause["exit_code"] = c.ExitCode()
+		}
+		chain = append(chain, cause)
+	}
+	for _, e := range unwrap(err) {
+		chain = appendChain(chain, e)
+	}
+	return chain
+}
+
+func unwrap(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	}
+	return nil
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(skip, pcs)]
+}
+
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -1,5 +1,14 @@
 package log // import "go.microcore.dev/framework/log"
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type OutputFormat string
 
 const (
@@ -12,4 +21,221 @@ const (
 	// FormatPretty writes colorized, developer-friendly logs, automatically disables
 	// color if output is not a terminal.
 	FormatPretty OutputFormat = "pretty"
+
+	// FormatLogfmt writes strict logfmt: lowercase levels, RFC 3339 UTC
+	// timestamps and keys restricted to characters valid in logfmt.
+	FormatLogfmt OutputFormat = "logfmt"
+
+	// FormatECS writes JSON using Elastic Common Schema field names
+	// (@timestamp, log.level, message, trace.id, span.id, error.*).
+	FormatECS OutputFormat = "ecs"
+
+	// FormatGCP writes JSON understood by Google Cloud Logging (severity,
+	// message, logging.googleapis.com/trace and spanId).
+	FormatGCP OutputFormat = "gcp"
 )
+
+// ECSVersion is the Elastic Common Schema version reported by FormatECS.
+const ECSVersion = "8.11.0"
+
+// Level names used by the logfmt and ECS formats. Levels between the
+// standard ones are rounded down.
+func lowerLevel(l slog.Level) string {
+	switch {
+	case l < slog.LevelDebug:
+		return "trace"
+	case l < slog.LevelInfo:
+		return "debug"
+	case l < slog.LevelWarn:
+		return "info"
+	case l < slog.LevelError:
+		return "warn"
+	case l < slog.LevelError+4:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
+
+// gcpSeverity maps a level to a Cloud Logging LogSeverity.
+func gcpSeverity(l slog.Level) string {
+	switch {
+	case l < slog.LevelInfo:
+		return "DEBUG"
+	case l < slog.LevelWarn:
+		return "INFO"
+	case l < slog.LevelError:
+		return "WARNING"
+	case l < slog.LevelError+4:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
+
+func logfmtReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.TimeKey:
+			if t, ok := a.Value.Any().(time.Time); ok {
+				return slog.String(a.Key, t.UTC().Format(time.RFC3339Nano))
+			}
+		case slog.LevelKey:
+			if l, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String(a.Key, lowerLevel(l))
+			}
+		}
+	}
+	a.Key = logfmtKey(a.Key)
+	return a
+}
+
+// logfmtKey replaces characters that are not allowed in a logfmt key.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func ecsReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		a.Key = "@timestamp"
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String("log.level", lowerLevel(l))
+		}
+	case slog.MessageKey:
+		a.Key = "message"
+	case slog.SourceKey:
+		a.Key = "log.origin"
+	case PackageKey:
+		a.Key = "log.logger"
+	case TraceIDKey:
+		a.Key = "trace.id"
+	case SpanIDKey:
+		a.Key = "span.id"
+	case TraceFlagsKey:
+		// No ECS field for trace flags
+		return slog.Attr{}
+	case "error":
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String("error.message", err.Error())
+		}
+	}
+	return a
+}
+
+// ecsHandler flattens the group of Err into the ECS error fields
+// (error.message, error.type, error.code, error.stack_trace), which
+// ReplaceAttr cannot do as it is not called for groups. Attributes inside
+// other groups are left as is, like ecsReplaceAttr does.
+type ecsHandler struct {
+	slog.Handler
+}
+
+func (h ecsHandler) Handle(ctx context.Context, r slog.Record) error {
+	flatten := false
+	r.Attrs(func(a slog.Attr) bool {
+		flatten = isErrGroup(a)
+		return !flatten
+	})
+	if !flatten {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	flat := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		flat.AddAttrs(ecsError(a)...)
+		return true
+	})
+	return h.Handler.Handle(ctx, flat)
+}
+
+func (h ecsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	flat := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		flat = append(flat, ecsError(a)...)
+	}
+	return ecsHandler{h.Handler.WithAttrs(flat)}
+}
+
+func (h ecsHandler) WithGroup(name string) slog.Handler {
+	return h.Handler.WithGroup(name)
+}
+
+func isErrGroup(a slog.Attr) bool {
+	return a.Key == ErrorKey && a.Value.Kind() == slog.KindGroup
+}
+
+// ecsError returns the attributes of the group of Err as ECS error fields,
+// and other attributes unchanged.
+func ecsError(a slog.Attr) []slog.Attr {
+	if !isErrGroup(a) {
+		return []slog.Attr{a}
+	}
+	group := a.Value.Group()
+	attrs := make([]slog.Attr, 0, len(group))
+	for _, attr := range group {
+		if attr.Key == "stack" {
+			attr.Key = "stack_trace"
+		}
+		attr.Key = ErrorKey + "." + attr.Key
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+func gcpReplaceAttr(project string) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+		switch a.Key {
+		case slog.LevelKey:
+			if l, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String("severity", gcpSeverity(l))
+			}
+		case slog.MessageKey:
+			a.Key = "message"
+		case slog.SourceKey:
+			a.Key = "logging.googleapis.com/sourceLocation"
+		case TraceIDKey:
+			trace := a.Value.String()
+			if project != "" {
+				trace = fmt.Sprintf("projects/%s/traces/%s", project, trace)
+			}
+			return slog.String("logging.googleapis.com/trace", trace)
+		case SpanIDKey:
+			a.Key = "logging.googleapis.com/spanId"
+		case TraceFlagsKey:
+			flags, _ := strconv.ParseUint(a.Value.String(), 16, 8)
+			return slog.Bool("logging.googleapis.com/trace_sampled", flags&1 == 1)
+		}
+		return a
+	}
+}
+
+// chainReplaceAttr applies the user function before the format mapping,
+// so that user code always sees the standard slog keys.
+func chainReplaceAttr(user, format func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	if user == nil {
+		return format
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		a = user(groups, a)
+		if a.Equal(slog.Attr{}) {
+			return a
+		}
+		return format(groups, a)
+	}
+}
@@ -17,6 +17,7 @@ Features:
 - Attributes and groups added via WithAttrs/WithGroup apply only to the specific logger and are
   wrapped in groups so that JSON or text logs reflect the proper attribute structure.
 - Changing the global backend via Config/SetBackend is automatically seen by ProxyHandler.
+- The package set by log.New is remembered, so Enabled applies per-package level overrides.
 - The Handler is safe for concurrent use, but unlike the standard slog.Handler, each Handle call
   involves processing attributes and groups, which has a slight impact on performance.
 
@@ -34,6 +35,7 @@ Conclusion:
 - ProxyHandler provides flexibility and convenience in managing the global backend and log structure.
 - This flexibility comes with a minor performance cost and extra allocations, especially when using groups.
 - For performance-critical paths, using a direct slog.Handler without ProxyHandler may be preferable.
+- When the output itself is slow (e.g. a blocked stderr pipe), use Options.Async (see AsyncHandler).
 */
 
 import (
@@ -47,6 +49,7 @@ type ProxyHandler struct {
 	backend *slog.Handler
 	attrs   []slog.Attr
 	groups  []string
+	pkg     string
 }
 
 var (
@@ -71,14 +74,42 @@ func NewProxyHandler() *ProxyHandler {
 }
 
 func (h *ProxyHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return (*h.backend).Enabled(ctx, level)
+	if h.enabled(ctx, level) {
+		return true
+	}
+	// The crash buffer retains records below the configured level
+	if c := crash.Load(); c != nil {
+		return c.enabled(level)
+	}
+	return false
 }
 
 func (h *ProxyHandler) Handle(ctx context.Context, r slog.Record) error {
-	// Fast return if there are no attributes or groups
-	if len(h.attrs) == 0 && len(h.groups) == 0 {
-		return (*h.backend).Handle(ctx, r)
+	if len(h.attrs) > 0 || len(h.groups) > 0 {
+		r = h.record(r)
+	}
+
+	if c := crash.Load(); c != nil {
+		if c.enabled(r.Level) {
+			_ = c.handler.Handle(ctx, r.Clone())
+		}
+		if !h.enabled(ctx, r.Level) {
+			return nil
+		}
+	}
+
+	return (*h.backend).Handle(ctx, r)
+}
+
+func (h *ProxyHandler) enabled(ctx context.Context, level slog.Level) bool {
+	if level < GetPackageLevel(h.pkg) {
+		return false
 	}
+	return (*h.backend).Enabled(ctx, level)
+}
+
+// record returns r with the attributes and groups of the handler applied.
+func (h *ProxyHandler) record(r slog.Record) slog.Record {
 
 	// Get a slice for attributes from the pool
 	attrsPtr := attrSlicePool.Get().(*[]slog.Attr)
@@ -120,17 +151,26 @@ func (h *ProxyHandler) Handle(ctx context.Context, r slog.Record) error {
 	*attrsPtr = attrs
 	attrSlicePool.Put(attrsPtr)
 
-	return (*h.backend).Handle(ctx, nr)
+	return nr
 }
 
 func (h *ProxyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	if len(attrs) == 0 {
 		return h
 	}
+	pkg := h.pkg
+	if len(h.groups) == 0 {
+		for _, a := range attrs {
+			if a.Key == PackageKey && a.Value.Kind() == slog.KindString {
+				pkg = a.Value.String()
+			}
+		}
+	}
 	return &ProxyHandler{
 		backend: h.backend,
 		attrs:   append(slices.Clone(h.attrs), attrs...),
 		groups:  h.groups,
+		pkg:     pkg,
 	}
 }
 
@@ -142,5 +182,6 @@ func (h *ProxyHandler) WithGroup(name string) slog.Handler {
 		backend: h.backend,
 		attrs:   h.attrs,
 		groups:  append(slices.Clone(h.groups), name),
+		pkg:     h.pkg,
 	}
 }
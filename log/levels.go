@@ -0,0 +1,305 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// PackageKey is the attribute key set by New. It is used to look up
+// per-package level overrides.
+const PackageKey = "pkg"
+
+type packageLevel struct {
+	prefix string
+	level  slog.Level
+}
+
+// expiry reverts a temporary level once its TTL has elapsed.
+type expiry struct {
+	expires time.Time
+	timer   *time.Timer
+
+	// Level to restore; exists is false when a package had no override.
+	restore slog.Level
+	exists  bool
+}
+
+var (
+	// packageLevels holds overrides sorted by prefix length, longest first,
+	// so the first match is the most specific one.
+	packageLevels atomic.Pointer[[]packageLevel]
+
+	// Pending reverts of temporary levels, guarded by mu.
+	levelExpiry     *expiry
+	packageExpiries = make(map[string]*expiry)
+)
+
+// SetPackageLevel overrides the level for loggers whose package equals
+// prefix or is nested under it (prefix + "/...").
+//
+// Example:
+//
+//	log.SetPackageLevel("go.microcore.dev/framework/transport/kafka", slog.LevelDebug)
+func SetPackageLevel(prefix string, l slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	cancelPackageExpiry(prefix)
+	levels := PackageLevels()
+	levels[prefix] = l
+	storePackageLevels(levels)
+}
+
+// UnsetPackageLevel removes the override for prefix.
+func UnsetPackageLevel(prefix string) {
+	mu.Lock()
+	defer mu.Unlock()
+	cancelPackageExpiry(prefix)
+	levels := PackageLevels()
+	delete(levels, prefix)
+	storePackageLevels(levels)
+}
+
+// SetPackageLevels replaces all per-package overrides.
+// A nil or empty map removes every override.
+func SetPackageLevels(levels map[string]slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	for prefix := range packageExpiries {
+		cancelPackageExpiry(prefix)
+	}
+	storePackageLevels(levels)
+}
+
+// storePackageLevels publishes overrides. The caller must hold mu.
+func storePackageLevels(levels map[string]slog.Level) {
+	list := make([]packageLevel, 0, len(levels))
+	for prefix, l := range levels {
+		list = append(list, packageLevel{prefix: prefix, level: l})
+	}
+	slices.SortFunc(list, func(a, b packageLevel) int {
+		if c := cmp.Compare(len(b.prefix), len(a.prefix)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.prefix, b.prefix)
+	})
+	packageLevels.Store(&list)
+}
+
+// SetPackageLevelsStr replaces all per-package overrides from a string in
+// the form "pkg=level,pkg=level", as used by the LOG_LEVELS environment
+// variable.
+//
+// Example:
+//
+//	log.SetPackageLevelsStr("go.microcore.dev/framework/transport/kafka=debug")
+func SetPackageLevelsStr(s string) error {
+	levels, err := ParsePackageLevels(s)
+	if err != nil {
+		return err
+	}
+	SetPackageLevels(levels)
+	return nil
+}
+
+// ParsePackageLevels parses a "pkg=level,pkg=level" string.
+// Level names are case-insensitive and follow slog.Level.UnmarshalText.
+func ParsePackageLevels(s string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(item, "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			return nil, fmt.Errorf("invalid package level %q", item)
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return nil, fmt.Errorf("invalid level for package %s: %w", prefix, err)
+		}
+		levels[prefix] = l
+	}
+	return levels, nil
+}
+
+// PackageLevels returns a copy of the current per-package overrides.
+func PackageLevels() map[string]slog.Level {
+	levels := make(map[string]slog.Level)
+	if list := packageLevels.Load(); list != nil {
+		for _, p := range *list {
+			levels[p.prefix] = p.level
+		}
+	}
+	return levels
+}
+
+// GetPackageLevel returns the effective level for a package: the most
+// specific override, or the global level if none matches.
+func GetPackageLevel(pkg string) slog.Level {
+	if list := packageLevels.Load(); list != nil && pkg != "" {
+		for _, p := range *list {
+			if matchPackage(pkg, p.prefix) {
+				return p.level
+			}
+		}
+	}
+	return level.Level()
+}
+
+// GetMinLevel returns the lowest level enabled for any package, that is the
+// minimum of the global level and all overrides. Consumers that cannot see
+// the package of a record, such as slog.Handler.Enabled on a backend,
+// use it as their threshold.
+func GetMinLevel() slog.Level {
+	l := level.Level()
+	if list := packageLevels.Load(); list != nil {
+		for _, p := range *list {
+			l = min(l, p.level)
+		}
+	}
+	return l
+}
+
+// SetLevelTemporary sets the global level for ttl, after which the level in
+// effect before the first pending temporary change is restored. Calling it
+// again before expiry extends the override; SetLevel cancels it.
+//
+// Example:
+//
+//	// Debug for ten minutes
+//	log.SetLevelTemporary(slog.LevelDebug, 10*time.Minute)
+func SetLevelTemporary(l slog.Level, ttl time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	e := &expiry{
+		expires: time.Now().Add(ttl),
+		restore: level.Level(),
+		exists:  true,
+	}
+	if levelExpiry != nil {
+		levelExpiry.timer.Stop()
+		e.restore = levelExpiry.restore
+	}
+	e.timer = time.AfterFunc(ttl, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if levelExpiry != e {
+			return
+		}
+		levelExpiry = nil
+		level.Set(e.restore)
+	})
+	levelExpiry = e
+
+	level.Set(l)
+}
+
+// SetPackageLevelTemporary works like SetPackageLevel but reverts the
+// override after ttl. See SetLevelTemporary.
+func SetPackageLevelTemporary(prefix string, l slog.Level, ttl time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	levels := PackageLevels()
+
+	e := &expiry{
+		expires: time.Now().Add(ttl),
+	}
+	e.restore, e.exists = levels[prefix]
+	if prev, ok := packageExpiries[prefix]; ok {
+		prev.timer.Stop()
+		e.restore, e.exists = prev.restore, prev.exists
+	}
+	e.timer = time.AfterFunc(ttl, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if packageExpiries[prefix] != e {
+			return
+		}
+		delete(packageExpiries, prefix)
+		levels := PackageLevels()
+		if e.exists {
+			levels[prefix] = e.restore
+		} else {
+			delete(levels, prefix)
+		}
+		storePackageLevels(levels)
+	})
+	packageExpiries[prefix] = e
+
+	levels[prefix] = l
+	storePackageLevels(levels)
+}
+
+// GetLevelExpiry returns when a temporary global level expires.
+// It reports false if the global level is not temporary.
+func GetLevelExpiry() (time.Time, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if levelExpiry == nil {
+		return time.Time{}, false
+	}
+	return levelExpiry.expires, true
+}
+
+// GetPackageLevelExpiry returns when a temporary override for prefix
+// expires. It reports false if the override is not temporary.
+func GetPackageLevelExpiry(prefix string) (time.Time, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := packageExpiries[prefix]
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.expires, true
+}
+
+// cancelLevelExpiry makes the current global level permanent.
+// The caller must hold mu.
+func cancelLevelExpiry() {
+	if levelExpiry != nil {
+		levelExpiry.timer.Stop()
+		levelExpiry = nil
+	}
+}
+
+// cancelPackageExpiry makes the current override for prefix permanent.
+// The caller must hold mu.
+func cancelPackageExpiry(prefix string) {
+	if e, ok := packageExpiries[prefix]; ok {
+		e.timer.Stop()
+		delete(packageExpiries, prefix)
+	}
+}
+
+// loadPackageLevelsEnv applies overrides from EnvPackageLevels, if set.
+func loadPackageLevelsEnv() error {
+	v := os.Getenv(EnvPackageLevels)
+	if v == "" {
+		SetPackageLevels(nil)
+		return nil
+	}
+	levels, err := ParsePackageLevels(v)
+	if err != nil {
+		SetPackageLevels(nil)
+		return fmt.Errorf("failed to parse %s: %w", EnvPackageLevels, err)
+	}
+	SetPackageLevels(levels)
+	return nil
+}
+
+func matchPackage(pkg, prefix string) bool {
+	if !strings.HasPrefix(pkg, prefix) {
+		return false
+	}
+	return len(pkg) == len(prefix) || pkg[len(prefix)] == '/' || strings.HasSuffix(prefix, "/")
+}
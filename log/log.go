@@ -17,29 +17,45 @@ Key features:
 
 2. **Dynamic configuration**
    - Change output destination (stdout, file, custom writer), format
-     (plain text, JSON, or pretty-colored), and attribute transformation
-     at runtime via Config().
+     (plain text, JSON, pretty-colored, logfmt, ECS or GCP Cloud Logging),
+     and attribute transformation at runtime via Config().
    - Changes take effect immediately across all loggers.
+   - NewRotatingFile provides a writer that rotates log files by size
+     and age.
 
 3. **Flexible backends**
    - Internally uses a proxy handler to delegate log events.
    - Updating the backend updates all existing loggers automatically.
    - Always use the package's helper functions to ensure logs go through
      the dynamic backend.
+   - SetRedaction masks sensitive attributes for every backend.
 
 4. **Structured and contextual logging**
    - Supports adding attributes and grouping fields for better organization.
    - Makes it easy to enrich logs with context or package-specific information.
+   - Err describes errors with their chain, codes and optional stack.
 
-5. **Global log level management**
+5. **Per-package levels**
+   - Loggers created with log.New can use a different level than the
+     global one, matched by package prefix.
+   - Overrides are read from LOG_LEVELS ("pkg=debug,pkg=warn") and can be
+     changed at runtime via SetPackageLevel / SetPackageLevels.
+
+6. **Multiple outputs**
+   - Additional handlers can be registered with AddSink, e.g. a local
+     console next to OpenTelemetry export.
+   - Each output can raise its own minimum level via Options.Level.
+
+7. **Global log level management**
    - The log level can be queried and updated at runtime.
    - Changes apply to all loggers created through the package.
 
-6. **Concurrency-safe**
+8. **Concurrency-safe**
    - Logging operations are safe to use from multiple goroutines.
    - Backend updates are protected by a mutex; reading logs is lock-free.
+   - Options.Async moves writes to slow outputs off the caller's goroutine.
 
-7. **Convenient helpers**
+9. **Convenient helpers**
    - Functions for common log levels: Debug, Info, Warn, Error,
      with or without context.
    - Use these instead of managing slog.Logger instances directly
@@ -75,12 +91,38 @@ type (
 		Writer      io.Writer
 		Format      OutputFormat
 		ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+		// DisableTraceContext turns off enrichment of records with
+		// trace_id, span_id and trace_flags (see TraceHandler).
+		DisableTraceContext bool
+
+		// Level optionally raises the minimum level of this output above
+		// the global level. Records must pass both. Useful together with
+		// AddSink, e.g. console at INFO while telemetry exports DEBUG.
+		//
+		// It cannot lower the level: records below the global or package
+		// level reach no output. Set the global level to the lowest level
+		// of all outputs, e.g. DEBUG in the example above.
+		Level slog.Leveler
+
+		// Sampling, if not nil, wraps the output with SamplingHandler to
+		// drop repetitive records.
+		Sampling *SamplingOptions
+
+		// Async, if not nil, wraps the output with AsyncHandler so that
+		// records are written from a background goroutine.
+		Async *AsyncOptions
+
+		// GCPProjectID is used by FormatGCP to build trace resource names.
+		// Defaults to the GOOGLE_CLOUD_PROJECT environment variable.
+		GCPProjectID string
 	}
 )
 
 var (
 	level   *slog.LevelVar
 	backend slog.Handler
+	primary slog.Handler
 	logger  *slog.Logger
 	mu      sync.Mutex
 )
@@ -92,10 +134,36 @@ func init() {
 // SetDefaultState resets the global logger to its default configuration.
 // Typically used at startup or in tests.
 func SetDefaultState() {
+	mu.Lock()
+
+	// Drop pending temporary levels
+	cancelLevelExpiry()
+	for prefix := range packageExpiries {
+		cancelPackageExpiry(prefix)
+	}
+
 	// Set default level
 	level = &slog.LevelVar{}
 	level.Set(DefaultLogLevel)
 
+	// Drop additional outputs and redaction
+	var replaced []slog.Handler
+	for _, s := range sinks {
+		replaced = append(replaced, s.owner)
+	}
+	sinks = nil
+	redaction = nil
+	closed := replacedHandlers(replaced...)
+
+	mu.Unlock()
+	closeHandlers(closed)
+
+	// Drop the crash buffer
+	DisableCrashBuffer()
+
+	// Set per-package levels from the environment
+	envErr := loadPackageLevelsEnv()
+
 	// Set default backend
 	Config(
 		Options{
@@ -110,14 +178,27 @@ func SetDefaultState() {
 
 	// Set default immutable logger
 	logger = slog.New(handler)
+
+	if envErr != nil {
+		logger.Warn(
+			"failed to load package levels, ignoring",
+			Err(envErr),
+		)
+	}
 }
 
 // SetBackend replaces the current backend for the global logger.
+// Sinks registered with AddSink are kept.
 // Usually, Config() is sufficient.
 func SetBackend(h slog.Handler) {
 	mu.Lock()
-	defer mu.Unlock()
-	backend = h
+	replaced := primary
+	primary = h
+	rebuildBackend()
+	closed := replacedHandlers(replaced)
+	mu.Unlock()
+
+	closeHandlers(closed)
 }
 
 // Config initializes and applies the global logger configuration.
@@ -143,15 +224,53 @@ func SetBackend(h slog.Handler) {
 //     Writes developer-friendly, colorized logs using tint.Handler.
 //     Colors are automatically disabled if the output is not a terminal.
 //
+//   - FormatLogfmt
+//     Writes strict logfmt with lowercase levels and UTC timestamps.
+//
+//   - FormatECS
+//     Writes JSON with Elastic Common Schema field names, e.g.
+//     @timestamp, log.level, message, log.logger, trace.id and span.id.
+//
+//   - FormatGCP
+//     Writes JSON for Google Cloud Logging: severity, message and
+//     logging.googleapis.com/trace, spanId and trace_sampled. Trace IDs are
+//     prefixed with projects/<GCPProjectID>/traces/ when a project is known.
+//
 // ReplaceAttr:
 //
 //   - If not nil, it is applied to each attribute before output.
 //     Can be used to mask sensitive data, rename fields, or modify messages.
 //   - If nil, attributes are written as-is.
+//   - For FormatLogfmt, FormatECS and FormatGCP it runs before the format's
+//     own field mapping, so it always sees the standard slog keys.
+//
+// Trace context:
+//
+//   - Unless DisableTraceContext is set, the backend is wrapped with
+//     TraceHandler, so records logged with a traced context (for example
+//     via LogAttrs or InfoContext) carry trace_id, span_id and trace_flags.
+//
+// Level:
+//
+//   - If not nil, the output only receives records at or above both the
+//     global level and Level.
+//
+// Sampling:
+//
+//   - If not nil, repetitive records are dropped according to
+//     SamplingOptions, see SamplingHandler. ERROR and above are kept.
+//
+// Async:
+//
+//   - If not nil, records are queued and written from a background
+//     goroutine, see AsyncHandler. The queue is flushed on shutdown, and
+//     when the handler is replaced by Config, SetBackend or AddSink, or
+//     removed by RemoveSink.
 //
 // Global effect:
 //
 //   - Config replaces the backend of the global logger.
+//   - Sinks added with AddSink keep receiving records.
 //   - All loggers created via log.New, log.With, or log.WithGroup
 //     immediately start using the new configuration.
 //
@@ -162,14 +281,27 @@ func SetBackend(h slog.Handler) {
 //       Format: log.FormatPretty,
 //   })
 func Config(opts Options) error {
+	handler, err := NewHandler(opts)
+	if err != nil {
+		return err
+	}
+	SetBackend(handler)
+	return nil
+}
+
+// NewHandler builds a handler from Options without installing it.
+// See Config for the meaning of each option. Combine with AddSink to
+// write to several outputs at once.
+func NewHandler(opts Options) (slog.Handler, error) {
 	var handler slog.Handler
+	leveler := minLevel{opts.Level}
 
 	switch opts.Format {
 	case FormatText:
 		handler = slog.NewTextHandler(
 			opts.Writer,
 			&slog.HandlerOptions{
-				Level:       level,
+				Level:       leveler,
 				ReplaceAttr: opts.ReplaceAttr,
 			},
 		)
@@ -177,40 +309,96 @@ func Config(opts Options) error {
 		handler = slog.NewJSONHandler(
 			opts.Writer,
 			&slog.HandlerOptions{
-				Level:       level,
+				Level:       leveler,
 				ReplaceAttr: opts.ReplaceAttr,
 			},
 		)
+	case FormatLogfmt:
+		handler = slog.NewTextHandler(
+			opts.Writer,
+			&slog.HandlerOptions{
+				Level:       leveler,
+				ReplaceAttr: chainReplaceAttr(opts.ReplaceAttr, logfmtReplaceAttr),
+			},
+		)
+	case FormatECS:
+		handler = slog.NewJSONHandler(
+			opts.Writer,
+			&slog.HandlerOptions{
+				Level:       leveler,
+				ReplaceAttr: chainReplaceAttr(opts.ReplaceAttr, ecsReplaceAttr),
+			},
+		).WithAttrs([]slog.Attr{
+			slog.String("ecs.version", ECSVersion),
+		})
+		handler = ecsHandler{handler}
+	case FormatGCP:
+		project := opts.GCPProjectID
+		if project == "" {
+			project = os.Getenv(EnvGCPProjectID)
+		}
+		handler = slog.NewJSONHandler(
+			opts.Writer,
+			&slog.HandlerOptions{
+				Level:       leveler,
+				ReplaceAttr: chainReplaceAttr(opts.ReplaceAttr, gcpReplaceAttr(project)),
+			},
+		)
 	case FormatPretty:
 		handler = tint.NewHandler(
 			opts.Writer,
 			&tint.Options{
-				Level:       level,
+				Level:       leveler,
 				TimeFormat:  DefaultTimeFormat,
 				NoColor:     !isTerminal(opts.Writer),
 				ReplaceAttr: opts.ReplaceAttr,
 			})
 	default:
-		return fmt.Errorf("format %s not implemented", opts.Format)
+		return nil, fmt.Errorf("format %s not implemented", opts.Format)
 	}
 
-	SetBackend(handler)
-	return nil
+	if opts.Async != nil {
+		async := NewAsyncHandler(handler, *opts.Async)
+		async.state.owned = true
+		handler = async
+	}
+
+	if opts.Sampling != nil {
+		sampling := NewSamplingHandler(handler, *opts.Sampling)
+		sampling.state.owned = true
+		handler = sampling
+	}
+
+	if !opts.DisableTraceContext {
+		handler = NewTraceHandler(handler)
+	}
+
+	return handler, nil
 }
 
 // SetLevel sets the global log level.
+// A pending SetLevelTemporary is cancelled.
 // Example: log.SetLevel(slog.LevelDebug)
 func SetLevel(l slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	cancelLevelExpiry()
 	level.Set(l)
 }
 
 // SetLevelStr sets the global log level from a string.
 // Example: log.SetLevelStr("INFO")
 func SetLevelStr(l string) error {
-	return level.UnmarshalText([]byte(l))
+	var v slog.Level
+	if err := v.UnmarshalText([]byte(l)); err != nil {
+		return err
+	}
+	SetLevel(v)
+	return nil
 }
 
 // GetLevel returns the current global log level.
+// Per-package overrides are not taken into account, see GetPackageLevel.
 func GetLevel() slog.Level {
 	return level.Level()
 }
@@ -219,7 +407,7 @@ func GetLevel() slog.Level {
 // Example: log.New("users").Info("user created")
 func New(pkg string) *slog.Logger {
 	return logger.With(
-		slog.String("pkg", pkg),
+		slog.String(PackageKey, pkg),
 	)
 }
 
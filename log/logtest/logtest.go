@@ -0,0 +1,256 @@
+package logtest // import "go.microcore.dev/framework/log/logtest"
+
+/*
+Package logtest captures records of the global logger in memory so tests can
+assert what was logged, including by framework packages such as shutdown or
+kafka:
+
+	func TestConsumer(t *testing.T) {
+	    logs := logtest.New(t)
+	    // ... run code that logs
+	    logs.Require(slog.LevelInfo, "sub: reader closed", slog.String("topic", "events"))
+	    logs.RequireNoErrors()
+	}
+
+New switches the global backend to the recorder and lowers the global level
+to DEBUG. On cleanup the global logger is reset with log.SetDefaultState, and
+if the test failed the captured records are written to the test log.
+*/
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+)
+
+type (
+	Recorder struct {
+		t testing.TB
+
+		mu      sync.Mutex
+		records []Record
+	}
+
+	// Record is a captured log record. Attributes of loggers created with
+	// With or WithGroup are included, groups as nested slog.Group values.
+	Record struct {
+		Time    time.Time
+		Level   slog.Level
+		Message string
+		Attrs   []slog.Attr
+	}
+
+	handler struct {
+		recorder *Recorder
+		attrs    []slog.Attr
+		groups   []string
+	}
+)
+
+// New captures all records of the global logger until the end of the test.
+func New(t testing.TB) *Recorder {
+	t.Helper()
+
+	r := &Recorder{t: t}
+
+	log.SetLevel(slog.LevelDebug)
+	log.SetBackend(log.NewTraceHandler(&handler{recorder: r}))
+
+	t.Cleanup(func() {
+		log.SetDefaultState()
+		if t.Failed() {
+			for _, rec := range r.Records() {
+				t.Logf("logtest: %s", rec)
+			}
+		}
+	})
+
+	return r
+}
+
+// Records returns all captured records in the order they were logged.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.records)
+}
+
+// Find returns the first record with the given level and message that has
+// all of the given attributes. An empty message matches any message.
+// Attributes inside groups are matched with dotted keys, e.g. "db.table".
+func (r *Recorder) Find(level slog.Level, msg string, attrs ...slog.Attr) (Record, bool) {
+	for _, rec := range r.Records() {
+		if rec.matches(level, msg, attrs) {
+			return rec, true
+		}
+	}
+	return Record{}, false
+}
+
+// Count returns the number of records matching as in Find.
+func (r *Recorder) Count(level slog.Level, msg string, attrs ...slog.Attr) int {
+	var n int
+	for _, rec := range r.Records() {
+		if rec.matches(level, msg, attrs) {
+			n++
+		}
+	}
+	return n
+}
+
+// Require fails the test immediately if no record matches as in Find.
+// It returns the matching record.
+func (r *Recorder) Require(level slog.Level, msg string, attrs ...slog.Attr) Record {
+	r.t.Helper()
+
+	rec, ok := r.Find(level, msg, attrs...)
+	if !ok {
+		r.t.Fatalf("log record %s %q with attributes %v not found, recorded:\n%s", level, msg, attrs, r.dump())
+	}
+	return rec
+}
+
+// RequireNoErrors fails the test immediately if a record at ERROR level or
+// above was captured.
+func (r *Recorder) RequireNoErrors() {
+	r.t.Helper()
+
+	for _, rec := range r.Records() {
+		if rec.Level >= slog.LevelError {
+			r.t.Fatalf("unexpected error log record: %s", rec)
+		}
+	}
+}
+
+// Reset discards all captured records.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	for _, rec := range r.Records() {
+		b.WriteString("  ")
+		b.WriteString(rec.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Attr returns the value of an attribute. Keys of nested groups are joined
+// with dots, e.g. "db.table".
+func (rec Record) Attr(key string) (slog.Value, bool) {
+	return findAttr(rec.Attrs, key)
+}
+
+func (rec Record) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", rec.Level, rec.Message)
+	for _, a := range rec.Attrs {
+		fmt.Fprintf(&b, " %s", a)
+	}
+	return b.String()
+}
+
+func (rec Record) matches(level slog.Level, msg string, attrs []slog.Attr) bool {
+	if rec.Level != level || (msg != "" && rec.Message != msg) {
+		return false
+	}
+	for _, want := range attrs {
+		got, ok := rec.Attr(want.Key)
+		if !ok || !got.Equal(want.Value.Resolve()) {
+			return false
+		}
+	}
+	return true
+}
+
+func findAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+		if a.Value.Kind() == slog.KindGroup && strings.HasPrefix(key, a.Key+".") {
+			if v, ok := findAttr(a.Value.Group(), key[len(a.Key)+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return slog.Value{}, false
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	attrs := []slog.Attr{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, resolve(a))
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	attrs = append(slices.Clone(h.attrs), attrs...)
+
+	h.recorder.mu.Lock()
+	defer h.recorder.mu.Unlock()
+	h.recorder.records = append(h.recorder.records, Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	resolved := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		resolved[i] = resolve(a)
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		resolved = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(resolved...)}}
+	}
+	return &handler{
+		recorder: h.recorder,
+		attrs:    append(slices.Clone(h.attrs), resolved...),
+		groups:   h.groups,
+	}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{
+		recorder: h.recorder,
+		attrs:    h.attrs,
+		groups:   append(slices.Clone(h.groups), name),
+	}
+}
+
+// resolve resolves LogValuers, also inside groups.
+func resolve(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = resolve(ga)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	}
+	return a
+}
@@ -0,0 +1,38 @@
+package logtest
+
+import (
+	"log/slog"
+	"testing"
+
+	"go.microcore.dev/framework/log"
+)
+
+func TestRecorder(t *testing.T) {
+	logs := New(t)
+
+	log.New("orders").Debug("query", slog.Group("db", slog.String("table", "orders"), slog.Int("rows", 3)))
+	log.Warn("slow")
+
+	rec := logs.Require(slog.LevelDebug, "query",
+		slog.String("pkg", "orders"),
+		slog.String("db.table", "orders"),
+		slog.Int("db.rows", 3),
+	)
+	if v, ok := rec.Attr("db.table"); !ok || v.String() != "orders" {
+		t.Fatalf("unexpected db.table: %v", v)
+	}
+
+	if _, ok := logs.Find(slog.LevelDebug, "query", slog.String("db.table", "users")); ok {
+		t.Fatal("unexpected match")
+	}
+	if n := logs.Count(slog.LevelWarn, ""); n != 1 {
+		t.Fatalf("expected 1 warning, got %d", n)
+	}
+
+	logs.RequireNoErrors()
+
+	logs.Reset()
+	if len(logs.Records()) != 0 {
+		t.Fatal("expected no records after reset")
+	}
+}
@@ -0,0 +1,262 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+type (
+	// RedactOptions configures RedactHandler.
+	RedactOptions struct {
+		// Keys are redacted when an attribute or group key equals one of
+		// them, ignoring case.
+		Keys []string
+
+		// KeyPatterns are redacted when an attribute or group key contains
+		// one of them, ignoring case.
+		KeyPatterns []string
+
+		// Detectors replace sensitive parts of string values, whatever
+		// their key.
+		Detectors []Detector
+
+		// Mask replaces redacted values. Defaults to DefaultRedactMask.
+		Mask string
+	}
+
+	// Detector returns s with every sensitive part replaced by mask.
+	Detector func(s string, mask string) string
+
+	// RedactHandler masks sensitive attributes, including attributes
+	// nested in groups, before passing records to the wrapped handler.
+	// Because it operates on slog attributes, it works the same for any
+	// backend. See SetRedaction to apply it to all outputs.
+	RedactHandler struct {
+		handler slog.Handler
+		opts    RedactOptions
+	}
+)
+
+// CredentialKeys are parameter names whose values are credentials, shared by
+// the log redaction and the masking of connection strings.
+var CredentialKeys = []string{
+	// User authentication
+	"password", "passfile", "require_auth",
+
+	// SSL/TLS
+	"sslkey", "sslpassword", "sslcert", "sslkeylogfile", "sslrootcert", "sslcrl",
+
+	// OAuth
+	"oauth_client_id", "oauth_client_secret",
+
+	// SCRAM
+	"scram_client_key", "scram_server_key",
+
+	// Kerberos / GSSAPI
+	"krbsrvname", "gsslib", "gssdelegation",
+}
+
+// DefaultRedactKeys are key names whose values are credentials.
+var DefaultRedactKeys = append([]string{
+	"pass", "pwd", "passphrase", "pin", "otp", "credential", "credentials",
+	"private_key", "privatekey", "client_secret", "access_key", "secret_key",
+	"session_id", "sessionid", "cvv", "cvc",
+}, CredentialKeys...)
+
+// DefaultRedactKeyPatterns are key fragments that mark a value as sensitive.
+var DefaultRedactKeyPatterns = []string{
+	"password", "passwd", "token", "authorization", "secret", "cookie", "api_key", "apikey",
+}
+
+var (
+	jwtRegexp    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerRegexp = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	cardRegexp   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	emailRegexp  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// Built-in value detectors.
+var (
+	// DetectJWT masks JSON Web Tokens.
+	DetectJWT Detector = func(s, mask string) string {
+		return jwtRegexp.ReplaceAllLiteralString(s, mask)
+	}
+
+	// DetectBearer masks "Bearer <token>" credentials.
+	DetectBearer Detector = func(s, mask string) string {
+		return bearerRegexp.ReplaceAllLiteralString(s, mask)
+	}
+
+	// DetectCardNumber masks payment card numbers that pass the Luhn check.
+	DetectCardNumber Detector = func(s, mask string) string {
+		return cardRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			if luhn(m) {
+				return mask
+			}
+			return m
+		})
+	}
+
+	// DetectEmail masks email addresses.
+	DetectEmail Detector = func(s, mask string) string {
+		return emailRegexp.ReplaceAllLiteralString(s, mask)
+	}
+)
+
+var redaction *RedactOptions
+
+// DefaultRedactOptions returns options that mask DefaultRedactKeys,
+// DefaultRedactKeyPatterns and all built-in detectors.
+func DefaultRedactOptions() RedactOptions {
+	return RedactOptions{
+		Keys:        DefaultRedactKeys,
+		KeyPatterns: DefaultRedactKeyPatterns,
+		Detectors:   []Detector{DetectJWT, DetectBearer, DetectCardNumber, DetectEmail},
+		Mask:        DefaultRedactMask,
+	}
+}
+
+// SetRedaction applies redaction to every output of the global logger,
+// including sinks added with AddSink. A nil value disables it.
+//
+// Example:
+//
+//	opts := log.DefaultRedactOptions()
+//	log.SetRedaction(&opts)
+func SetRedaction(opts *RedactOptions) {
+	mu.Lock()
+	defer mu.Unlock()
+	redaction = opts
+	rebuildBackend()
+}
+
+func NewRedactHandler(handler slog.Handler, opts RedactOptions) *RedactHandler {
+	if opts.Mask == "" {
+		opts.Mask = DefaultRedactMask
+	}
+	keys := make([]string, len(opts.Keys))
+	for i, k := range opts.Keys {
+		keys[i] = strings.ToLower(k)
+	}
+	patterns := make([]string, len(opts.KeyPatterns))
+	for i, p := range opts.KeyPatterns {
+		patterns[i] = strings.ToLower(p)
+	}
+	opts.Keys, opts.KeyPatterns = keys, patterns
+	return &RedactHandler{
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.handler.Handle(ctx, nr)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+	return &RedactHandler{handler: h.handler.WithAttrs(redacted), opts: h.opts}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{handler: h.handler.WithGroup(name), opts: h.opts}
+}
+
+func (h *RedactHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if h.sensitiveKey(a.Key) {
+		return slog.String(a.Key, h.opts.Mask)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = h.redact(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindString:
+		if s, ok := h.detect(a.Value.String()); ok {
+			return slog.String(a.Key, s)
+		}
+	case slog.KindAny:
+		var s string
+		switch v := a.Value.Any().(type) {
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			return a
+		}
+		if s, ok := h.detect(s); ok {
+			return slog.String(a.Key, s)
+		}
+	}
+	return a
+}
+
+func (h *RedactHandler) sensitiveKey(key string) bool {
+	if len(h.opts.Keys) == 0 && len(h.opts.KeyPatterns) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, k := range h.opts.Keys {
+		if key == k {
+			return true
+		}
+	}
+	for _, p := range h.opts.KeyPatterns {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// detect runs all detectors on s and reports whether anything was masked.
+func (h *RedactHandler) detect(s string) (string, bool) {
+	out := s
+	for _, d := range h.opts.Detectors {
+		out = d(out, h.opts.Mask)
+	}
+	return out, out != s
+}
+
+// luhn validates a card number, ignoring spaces and dashes.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
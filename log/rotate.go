@@ -0,0 +1,400 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// RotateOptions configures RotatingFile.
+	RotateOptions struct {
+		// Filename is the path of the active log file.
+		Filename string
+
+		// MaxSize is the size in bytes after which the file is rotated.
+		// Zero disables size based rotation.
+		MaxSize int64
+
+		// MaxAge is the age after which the file is rotated.
+		// Zero disables age based rotation.
+		MaxAge time.Duration
+
+		// MaxBackups is the number of rotated files to keep.
+		// Zero keeps all of them.
+		MaxBackups int
+
+		// Compress gzips rotated files.
+		Compress bool
+
+		// ReopenOnSIGHUP reopens Filename when the process receives SIGHUP,
+		// so external tools like logrotate can move the file away.
+		ReopenOnSIGHUP bool
+
+		// Perm is used when creating files. Defaults to DefaultRotatePerm.
+		Perm os.FileMode
+	}
+
+	// RotatingFile is an io.WriteCloser that writes to a file and rotates
+	// it by size and age. Rotated files are named
+	// <name>-<timestamp><ext>[.gz] next to the active file. It is safe for
+	// concurrent use and can be passed as Options.Writer.
+	RotatingFile struct {
+		opts RotateOptions
+
+		mu       sync.Mutex
+		file     *os.File
+		size     int64
+		openedAt time.Time
+
+		mill    sync.WaitGroup // compression and cleanup of backups
+		millMu  sync.Mutex
+		signals chan os.Signal
+		done    chan struct{}
+	}
+)
+
+const rotateTimeFormat = "20060102T150405.000"
+
+// rename moves the active file to its backup, replaced in tests.
+var rename = os.Rename
+
+// NewRotatingFile opens opts.Filename for appending, creating it and its
+// directory if needed.
+//
+// Example:
+//
+//	w, err := log.NewRotatingFile(log.RotateOptions{
+//		Filename:   "/var/log/app/app.log",
+//		MaxSize:    100 << 20,
+//		MaxBackups: 7,
+//		Compress:   true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	log.Config(log.Options{Writer: w, Format: log.FormatJSON})
+func NewRotatingFile(opts RotateOptions) (*RotatingFile, error) {
+	if opts.Filename == "" {
+		return nil, errors.New("filename is empty")
+	}
+	if opts.Perm == 0 {
+		opts.Perm = DefaultRotatePerm
+	}
+
+	f := &RotatingFile{
+		opts: opts,
+		done: make(chan struct{}),
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	if opts.ReopenOnSIGHUP {
+		f.signals = make(chan os.Signal, 1)
+		signal.Notify(f.signals, syscall.SIGHUP)
+		go f.watchSignals()
+	}
+
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.active(); err != nil {
+		return 0, err
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the active file, renames it and opens a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.active(); err != nil {
+		return err
+	}
+	return f.rotate()
+}
+
+// Reopen closes and reopens Filename, for example after it has been moved
+// by logrotate.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.active(); err != nil {
+		return err
+	}
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Sync commits the active file to stable storage.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.active(); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close closes the active file and waits for pending compression.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		return os.ErrClosed
+	default:
+	}
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	if f.signals != nil {
+		signal.Stop(f.signals)
+	}
+	close(f.done)
+	f.mu.Unlock()
+
+	f.mill.Wait()
+	return err
+}
+
+func (f *RotatingFile) watchSignals() {
+	for {
+		select {
+		case <-f.signals:
+			if err := f.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "log: failed to reopen %s: %v\n", f.opts.Filename, err)
+			}
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// shouldRotate reports whether writing n bytes requires a rotation.
+// The caller must hold mu.
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && time.Since(f.openedAt) >= f.opts.MaxAge
+}
+
+// active opens the active file again if a rotation failed to, and returns
+// os.ErrClosed once Close has been called. The caller must hold mu.
+func (f *RotatingFile) active() error {
+	select {
+	case <-f.done:
+		return os.ErrClosed
+	default:
+	}
+	if f.file != nil {
+		return nil
+	}
+	return f.open()
+}
+
+// open opens the active file for appending. The caller must hold mu.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.opts.Filename), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.opts.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.opts.Perm)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// rotate moves the active file to a backup and opens a new one.
+//
+// If the file cannot be moved, it is reopened and the rotation is retried
+// after another MaxSize bytes or MaxAge, so that the error is returned once
+// rather than by every write. If no file can be opened, the next write
+// retries. The caller must hold mu.
+func (f *RotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return errors.Join(err, f.open())
+	}
+
+	backup := f.backupName(time.Now())
+	if err := rename(f.opts.Filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("failed to rename log file: %w", err)
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		f.size = 0
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.mill.Add(1)
+	go func() {
+		defer f.mill.Done()
+		f.millMu.Lock()
+		defer f.millMu.Unlock()
+		if err := f.millBackups(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "log: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// backupName returns an unused backup path for t, moving forward in time
+// if several rotations happen within the same millisecond.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(f.opts.Filename)
+	ext := filepath.Ext(f.opts.Filename)
+	name := strings.TrimSuffix(filepath.Base(f.opts.Filename), ext)
+	for {
+		path := filepath.Join(dir, name+"-"+t.Format(rotateTimeFormat)+ext)
+		if !exists(path) && !exists(path+".gz") {
+			return path
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// millBackups compresses the new backup and removes the oldest ones.
+func (f *RotatingFile) millBackups(backup string) error {
+	var errs []error
+
+	// The backup may already have been removed by the milling of a newer one
+	if f.opts.Compress {
+		if err := compressFile(backup, f.opts.Perm); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to compress %s: %w", backup, err))
+		}
+	}
+
+	if f.opts.MaxBackups > 0 {
+		backups, err := f.backups()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for i := f.opts.MaxBackups; i < len(backups); i++ {
+			if err := os.Remove(backups[i]); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", backups[i], err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups returns rotated files, newest first.
+func (f *RotatingFile) backups() ([]string, error) {
+	dir := filepath.Dir(f.opts.Filename)
+	ext := filepath.Ext(f.opts.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.opts.Filename), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log directory: %w", err)
+	}
+
+	type backup struct {
+		path string
+		t    time.Time
+	}
+	var list []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], ".gz"), ext)
+		t, err := time.Parse(rotateTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+		list = append(list, backup{path: filepath.Join(dir, name), t: t})
+	}
+
+	slices.SortFunc(list, func(a, b backup) int {
+		return b.t.Compare(a.t)
+	})
+
+	paths := make([]string, len(list))
+	for i, b := range list {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
+
+func compressFile(src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(src+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		os.Remove(src + ".gz")
+		return err
+	}
+	if err := errors.Join(gz.Close(), out.Close()); err != nil {
+		os.Remove(src + ".gz")
+		return err
+	}
+
+	return os.Remove(src)
+}
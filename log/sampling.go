@@ -0,0 +1,255 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// SamplingOptions configures SamplingHandler. Zero values disable the
+	// corresponding mode.
+	SamplingOptions struct {
+		// Interval over which First and Thereafter are counted.
+		// Defaults to DefaultSamplingInterval.
+		Interval time.Duration
+
+		// First records with the same level and message are kept in each
+		// interval, after that only every Thereafter-th one. With
+		// Thereafter set to zero the rest of the interval is dropped.
+		// Message sampling is disabled if First is zero.
+		First      int
+		Thereafter int
+
+		// Limits caps the rate of records per level with a token bucket.
+		Limits map[slog.Level]RateLimit
+
+		// KeepLevel is the level at and above which records are never
+		// dropped. Defaults to DefaultSamplingKeepLevel.
+		KeepLevel slog.Leveler
+
+		// ReportInterval is the period of the summary records about dropped
+		// records. Defaults to DefaultSamplingReportInterval.
+		ReportInterval time.Duration
+	}
+
+	// RateLimit describes a token bucket: Rate records per second on
+	// average, with bursts of up to Burst records.
+	RateLimit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// SamplingHandler drops repetitive records before they reach the
+	// wrapped handler. Dropped records are counted (see DroppedRecords)
+	// and summarized in a WARN record written to the wrapped handler at
+	// the end of each ReportInterval in which records were dropped, from a
+	// timer. Handlers built by NewHandler are closed once replaced or
+	// removed from the global logger.
+	SamplingHandler struct {
+		handler slog.Handler
+		state   *samplingState
+	}
+
+	samplingState struct {
+		opts    SamplingOptions
+		handler slog.Handler
+
+		mu          sync.Mutex
+		windowStart time.Time
+		messages    map[messageKey]int
+		buckets     map[slog.Level]*bucket
+		dropped     int64 // since the last summary
+		total       int64
+		lastReport  time.Time
+		timer       *time.Timer
+		closed      bool
+		owned       bool // built by NewHandler
+	}
+
+	messageKey struct {
+		level slog.Level
+		msg   string
+	}
+
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// Reasons reported by DroppedRecords.
+const (
+	DropReasonSampling  = "sampling"
+	DropReasonRateLimit = "rate_limit"
+)
+
+var droppedRecords sync.Map // reason -> *atomic.Int64
+
+func NewSamplingHandler(handler slog.Handler, opts SamplingOptions) *SamplingHandler {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultSamplingInterval
+	}
+	if opts.KeepLevel == nil {
+		opts.KeepLevel = DefaultSamplingKeepLevel
+	}
+	if opts.ReportInterval <= 0 {
+		opts.ReportInterval = DefaultSamplingReportInterval
+	}
+	now := time.Now()
+	return &SamplingHandler{
+		handler: handler,
+		state: &samplingState{
+			opts:        opts,
+			handler:     handler,
+			windowStart: now,
+			messages:    make(map[messageKey]int),
+			buckets:     make(map[slog.Level]*bucket),
+			lastReport:  now,
+		},
+	}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.state.sample(r) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithAttrs(attrs), state: h.state}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithGroup(name), state: h.state}
+}
+
+// Dropped returns the number of records this handler, and the handlers
+// derived from it, have dropped.
+func (h *SamplingHandler) Dropped() int64 {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return h.state.total
+}
+
+// Close writes the summary of the records dropped since the last one and
+// stops the report timer. Records dropped afterwards are counted but not
+// summarized.
+func (h *SamplingHandler) Close() {
+	h.state.mu.Lock()
+	h.state.closed = true
+	if h.state.timer != nil {
+		h.state.timer.Stop()
+		h.state.timer = nil
+	}
+	h.state.mu.Unlock()
+
+	h.state.report()
+}
+
+// report writes a summary of the records dropped since the last one. It
+// runs from the timer started by the first of them.
+func (s *samplingState) report() {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.dropped = 0
+	s.lastReport = time.Now()
+	s.timer = nil
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	if dropped == 0 || !s.handler.Enabled(ctx, slog.LevelWarn) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "log records dropped by sampling", 0)
+	r.AddAttrs(
+		slog.Int64("dropped", dropped),
+		slog.Duration("period", s.opts.ReportInterval),
+	)
+	_ = s.handler.Handle(ctx, r)
+}
+
+// sample decides whether to keep r, and schedules a summary of the
+// dropped records.
+func (s *samplingState) sample(r slog.Record) (keep bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	keep = true
+	reason := ""
+
+	if r.Level < s.opts.KeepLevel.Level() {
+		if s.opts.First > 0 {
+			if now.Sub(s.windowStart) >= s.opts.Interval {
+				s.windowStart = now
+				clear(s.messages)
+			}
+			key := messageKey{level: r.Level, msg: r.Message}
+			n := s.messages[key] + 1
+			s.messages[key] = n
+			if n > s.opts.First && (s.opts.Thereafter <= 0 || (n-s.opts.First)%s.opts.Thereafter != 0) {
+				keep, reason = false, DropReasonSampling
+			}
+		}
+		if keep {
+			if limit, ok := s.opts.Limits[r.Level]; ok && !s.take(r.Level, limit, now) {
+				keep, reason = false, DropReasonRateLimit
+			}
+		}
+	}
+
+	if !keep {
+		s.dropped++
+		s.total++
+		addDroppedRecords(reason, 1)
+		if s.timer == nil && !s.closed {
+			s.timer = time.AfterFunc(max(0, s.lastReport.Add(s.opts.ReportInterval).Sub(now)), s.report)
+		}
+	}
+
+	return keep
+}
+
+// take consumes a token from the bucket for level. The caller must hold mu.
+func (s *samplingState) take(level slog.Level, limit RateLimit, now time.Time) bool {
+	b, ok := s.buckets[level]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[level] = b
+	}
+	b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// DroppedRecords returns the number of records dropped by handlers of this
+// package since the process started, by reason. Telemetry exports it as
+// the log.records.dropped metric.
+func DroppedRecords() map[string]int64 {
+	res := make(map[string]int64)
+	droppedRecords.Range(func(k, v any) bool {
+		res[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return res
+}
+
+func addDroppedRecords(reason string, n int64) {
+	v, ok := droppedRecords.Load(reason)
+	if !ok {
+		v, _ = droppedRecords.LoadOrStore(reason, &atomic.Int64{})
+	}
+	v.(*atomic.Int64).Add(n)
+}
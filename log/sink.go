@@ -0,0 +1,208 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+type (
+	// FanoutHandler dispatches every record to several handlers. A handler
+	// only receives records it is enabled for, so each output can have its
+	// own level, format and destination.
+	FanoutHandler struct {
+		handlers []slog.Handler
+	}
+
+	// LevelHandler gates a handler by a minimum level on top of the global
+	// level. See NewLevelHandler.
+	LevelHandler struct {
+		handler slog.Handler
+		level   slog.Leveler
+	}
+
+	sink struct {
+		name    string
+		handler slog.Handler
+		// owner is the handler passed to AddSink.
+		owner slog.Handler
+	}
+)
+
+var sinks []sink
+
+func NewFanoutHandler(handlers ...slog.Handler) *FanoutHandler {
+	return &FanoutHandler{
+		handlers: handlers,
+	}
+}
+
+func (h *FanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *FanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		// Each handler gets its own copy, as handlers may add attributes.
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *FanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return NewFanoutHandler(handlers...)
+}
+
+func (h *FanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return NewFanoutHandler(handlers...)
+}
+
+// NewLevelHandler returns a handler that is enabled only for records at or
+// above both the lowest enabled level (see GetMinLevel) and the given level.
+// A nil level means the lowest enabled level alone.
+func NewLevelHandler(handler slog.Handler, level slog.Leveler) *LevelHandler {
+	return &LevelHandler{
+		handler: handler,
+		level:   minLevel{level},
+	}
+}
+
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.handler.Enabled(ctx, level)
+}
+
+func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{handler: h.handler.WithGroup(name), level: h.level}
+}
+
+// minLevel is a slog.Leveler that never goes below the lowest enabled
+// level. Per-package levels are enforced by ProxyHandler, which knows the
+// package of each logger.
+type minLevel struct {
+	level slog.Leveler
+}
+
+func (l minLevel) Level() slog.Level {
+	floor := GetMinLevel()
+	if l.level == nil {
+		return floor
+	}
+	return max(floor, l.level.Level())
+}
+
+// AddSink registers an additional output next to the backend configured by
+// Config or SetBackend. Records are delivered to every output they are
+// enabled for. The handler is gated by the global and per-package levels; use
+// NewHandler with Options.Level, or NewLevelHandler, to raise its
+// minimum level independently.
+//
+// A sink with the same name is replaced.
+//
+// Example:
+//
+//	// Pretty output on the console at INFO, JSON file at DEBUG
+//	log.SetLevel(slog.LevelDebug)
+//	log.Config(log.Options{Writer: os.Stderr, Format: log.FormatPretty, Level: slog.LevelInfo})
+//	h, _ := log.NewHandler(log.Options{Writer: file, Format: log.FormatJSON})
+//	log.AddSink("file", h)
+func AddSink(name string, handler slog.Handler) {
+	mu.Lock()
+
+	s := sink{
+		name:    name,
+		handler: NewLevelHandler(handler, nil),
+		owner:   handler,
+	}
+
+	var replaced slog.Handler
+	i := slices.IndexFunc(sinks, func(s sink) bool { return s.name == name })
+	if i >= 0 {
+		replaced = sinks[i].owner
+		sinks = slices.Clone(sinks)
+		sinks[i] = s
+	} else {
+		sinks = append(slices.Clone(sinks), s)
+	}
+
+	rebuildBackend()
+	var closed []slog.Handler
+	if replaced != nil {
+		closed = replacedHandlers(replaced)
+	}
+	mu.Unlock()
+
+	closeHandlers(closed)
+}
+
+// RemoveSink unregisters an output added with AddSink.
+// It reports whether the sink existed.
+func RemoveSink(name string) bool {
+	mu.Lock()
+
+	i := slices.IndexFunc(sinks, func(s sink) bool { return s.name == name })
+	if i < 0 {
+		mu.Unlock()
+		return false
+	}
+	removed := sinks[i].owner
+	sinks = slices.Delete(slices.Clone(sinks), i, i+1)
+
+	rebuildBackend()
+	closed := replacedHandlers(removed)
+	mu.Unlock()
+
+	closeHandlers(closed)
+	return true
+}
+
+// rebuildBackend combines the primary backend, the registered sinks and
+// redaction into the handler used by ProxyHandler, and applies redaction to
+// the crash buffer. The caller must hold mu.
+func rebuildBackend() {
+	backend = primary
+	if len(sinks) > 0 {
+		handlers := []slog.Handler{primary}
+		for _, s := range sinks {
+			handlers = append(handlers, s.handler)
+		}
+		backend = NewFanoutHandler(handlers...)
+	}
+	if redaction != nil {
+		backend = NewRedactHandler(backend, *redaction)
+	}
+	if c := crash.Load(); c != nil {
+		crash.Store(&crashBuffer{
+			opts:    c.opts,
+			ring:    c.ring,
+			handler: newCrashHandler(c.ring),
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package log // import "go.microcore.dev/framework/log"
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey is the attribute key of the W3C trace ID added by TraceHandler.
+	TraceIDKey = "trace_id"
+
+	// SpanIDKey is the attribute key of the span ID added by TraceHandler.
+	SpanIDKey = "span_id"
+
+	// TraceFlagsKey is the attribute key of the W3C trace flags added by TraceHandler.
+	TraceFlagsKey = "trace_flags"
+)
+
+// TraceHandler is a slog.Handler wrapper that enriches every record with the
+// trace ID, span ID and trace flags of the span found in the record context.
+//
+// Records logged without a context, or with a context that carries no valid
+// span, are passed through unchanged. This makes the wrapper free to keep
+// enabled when telemetry is not configured.
+//
+// Config wraps the text, JSON and pretty backends with TraceHandler unless
+// Options.DisableTraceContext is set. The otelslog bridge does not need it,
+// as OpenTelemetry log records carry the span context natively.
+type TraceHandler struct {
+	handler slog.Handler
+}
+
+func NewTraceHandler(handler slog.Handler) *TraceHandler {
+	return &TraceHandler{
+		handler: handler,
+	}
+}
+
+func (h *TraceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.handler.Handle(ctx, r)
+	}
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return h.handler.Handle(ctx, r)
+	}
+
+	r.AddAttrs(
+		slog.String(TraceIDKey, sc.TraceID().String()),
+		slog.String(SpanIDKey, sc.SpanID().String()),
+		slog.String(TraceFlagsKey, sc.TraceFlags().String()),
+	)
+
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewTraceHandler(h.handler.WithAttrs(attrs))
+}
+
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return NewTraceHandler(h.handler.WithGroup(name))
+}
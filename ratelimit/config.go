@@ -0,0 +1,17 @@
+package ratelimit // import "go.microcore.dev/framework/ratelimit"
+
+import (
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/ratelimit"
+
+	// Prefix of the keys of RedisStore.
+	DefaultRedisPrefix = "ratelimit:"
+
+	// Interval of the removal of expired keys from MemoryStore.
+	DefaultMemorySweepInterval = time.Minute
+)
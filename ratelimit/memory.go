@@ -0,0 +1,116 @@
+package ratelimit // import "go.microcore.dev/framework/ratelimit"
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+type (
+	// MemoryStore keeps the state of limiters in the memory of the process.
+	// Expired keys are removed every DefaultMemorySweepInterval.
+	MemoryStore struct {
+		mu        sync.Mutex
+		entries   map[string]*memoryEntry
+		lastSweep time.Time
+		now       func() time.Time
+	}
+
+	memoryEntry struct {
+		// Token bucket
+		tokens float64
+		last   time.Time
+
+		// Sliding window
+		window   int64
+		previous int
+		current  int
+
+		expires time.Time
+	}
+)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) TakeToken(_ context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e := s.entry(key, now)
+	if e.last.IsZero() {
+		e.tokens = float64(burst)
+	} else {
+		e.tokens = math.Min(float64(burst), e.tokens+now.Sub(e.last).Seconds()*rate)
+	}
+	e.last = now
+
+	allowed := e.tokens >= 1
+	if allowed {
+		e.tokens--
+	}
+	e.expires = now.Add(seconds((float64(burst) - e.tokens) / rate))
+
+	return e.tokens, allowed, nil
+}
+
+func (s *MemoryStore) IncrementWindow(_ context.Context, key string, limit int, window time.Duration) (WindowState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e := s.entry(key, now)
+
+	current := now.UnixNano() / int64(window)
+	switch e.window {
+	case current:
+	case current - 1:
+		e.previous, e.current = e.current, 0
+	default:
+		e.previous, e.current = 0, 0
+	}
+	e.window = current
+
+	elapsed := time.Duration(now.UnixNano() - current*int64(window))
+	count := float64(e.previous)*float64(window-elapsed)/float64(window) + float64(e.current)
+
+	allowed := count+1 <= float64(limit)
+	if allowed {
+		e.current++
+	}
+	e.expires = time.Unix(0, (current+2)*int64(window))
+
+	return WindowState{
+		Previous: e.previous,
+		Current:  e.current,
+		Elapsed:  elapsed,
+	}, allowed, nil
+}
+
+// entry returns the entry of key, removing expired entries every
+// DefaultMemorySweepInterval.
+func (s *MemoryStore) entry(key string, now time.Time) *memoryEntry {
+	if now.Sub(s.lastSweep) >= DefaultMemorySweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	return e
+}
@@ -0,0 +1,42 @@
+package ratelimit // import "go.microcore.dev/framework/ratelimit"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+type Option func(*limiter)
+
+// WithStore sets the store of the limiter state. Defaults to a MemoryStore
+// owned by the limiter.
+func WithStore(store Store) Option {
+	return func(l *limiter) {
+		l.store = store
+	}
+}
+
+// WithName sets the namespace of the keys of the limiter in its store.
+// Limiters sharing a store, e.g. a RedisStore, with the same algorithm and
+// settings share their counters unless they have different names.
+func WithName(name string) Option {
+	return func(l *limiter) {
+		l.name = name
+	}
+}
+
+// WithBurst sets the bucket capacity of a token bucket limiter, the number
+// of requests allowed at once. Defaults to its limit.
+func WithBurst(burst int) Option {
+	return func(l *limiter) {
+		l.burst = burst
+	}
+}
+
+type RedisStoreOption func(*RedisStore)
+
+// WithRedisPrefix sets the prefix of the keys of the store. Defaults to
+// DefaultRedisPrefix.
+func WithRedisPrefix(prefix string) RedisStoreOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
@@ -0,0 +1,225 @@
+package ratelimit // import "go.microcore.dev/framework/ratelimit"
+
+/*
+Package ratelimit limits the rate of events, such as requests, per key.
+
+Two algorithms are provided:
+
+  - NewTokenBucket allows limit events per period on average and bursts of
+    up to the bucket capacity (WithBurst, the limit by default).
+  - NewSlidingWindow allows limit events in any window, approximated by
+    weighting the count of the previous fixed window.
+
+The state of the limiters is kept in a Store: a MemoryStore local to the
+process (the default) or a RedisStore shared by all instances of a service:
+
+	limiter := ratelimit.NewSlidingWindow(100, time.Minute,
+	    ratelimit.WithStore(ratelimit.NewRedisStore(redisManager)),
+	    ratelimit.WithName("login"),
+	)
+
+	res, err := limiter.Allow(ctx, ip)
+	if err == nil && !res.Allowed {
+	    // retry after res.RetryAfter
+	}
+*/
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+)
+
+type (
+	// Limiter decides whether an event of a key is allowed.
+	Limiter interface {
+		Allow(ctx context.Context, key string) (Result, error)
+	}
+
+	// Result is the decision of a Limiter and the quota of the key.
+	Result struct {
+		Allowed bool
+		// Limit is the number of events allowed per Window.
+		Limit  int
+		Window time.Duration
+		// Burst is the number of events allowed at once, which differs
+		// from Limit for token buckets with WithBurst.
+		Burst int
+		// Remaining is the number of events allowed right now.
+		Remaining int
+		// Reset is the time until the quota is fully available again.
+		Reset time.Duration
+		// RetryAfter is the time until the next event is allowed if this
+		// one was not.
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the state of limiters. Each operation must atomically
+	// update the state of key and decide whether the event is allowed.
+	Store interface {
+		// TakeToken refills the bucket of key at rate tokens per second, up
+		// to burst tokens, and takes a token if one is available. It returns
+		// the tokens left in the bucket.
+		TakeToken(ctx context.Context, key string, rate float64, burst int) (tokens float64, allowed bool, err error)
+		// IncrementWindow increments the count of the current fixed window
+		// of key if the weighted count of the previous and current windows
+		// is below limit. It returns both counts and the time elapsed in the
+		// current window.
+		IncrementWindow(ctx context.Context, key string, limit int, window time.Duration) (WindowState, bool, error)
+	}
+
+	// WindowState is the state of a key of a sliding window limiter.
+	WindowState struct {
+		Previous int
+		Current  int
+		Elapsed  time.Duration
+	}
+
+	limiter struct {
+		store Store
+		name  string
+		limit int
+		burst int
+		// period of the token bucket or window of the sliding window
+		period time.Duration
+		allow  func(l *limiter, ctx context.Context, key string) (Result, error)
+	}
+)
+
+var logger = log.New(pkg)
+
+// NewTokenBucket returns a token bucket limiter allowing limit events per
+// period, in bursts of up to WithBurst events. A limit or period that is not
+// positive is replaced by 1 event or 1 second.
+func NewTokenBucket(limit int, period time.Duration, opts ...Option) Limiter {
+	l := newLimiter("token_bucket", limit, period, (*limiter).allowTokenBucket, opts...)
+	if l.burst <= 0 {
+		l.burst = l.limit
+	}
+	return l
+}
+
+// NewSlidingWindow returns a sliding window limiter allowing limit events
+// in any window. A limit or window that is not positive is replaced by 1
+// event or 1 second.
+func NewSlidingWindow(limit int, window time.Duration, opts ...Option) Limiter {
+	return newLimiter("sliding_window", limit, window, (*limiter).allowSlidingWindow, opts...)
+}
+
+func newLimiter(
+	algorithm string,
+	limit int,
+	period time.Duration,
+	allow func(l *limiter, ctx context.Context, key string) (Result, error),
+	opts ...Option,
+) *limiter {
+	if limit <= 0 || period <= 0 {
+		logger.Error(
+			"invalid rate limit, using the minimum",
+			slog.String("algorithm", algorithm),
+			slog.Int("limit", limit),
+			slog.Duration("period", period),
+		)
+		if limit <= 0 {
+			limit = 1
+		}
+		if period <= 0 {
+			period = time.Second
+		}
+	}
+
+	l := &limiter{
+		limit:  limit,
+		period: period,
+		allow:  allow,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+	if l.name == "" {
+		l.name = fmt.Sprintf("%s:%d:%s", algorithm, limit, period)
+	}
+
+	return l
+}
+
+func (l *limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.allow(l, ctx, l.name+":"+key)
+}
+
+func (l *limiter) allowTokenBucket(ctx context.Context, key string) (Result, error) {
+	rate := float64(l.limit) / l.period.Seconds()
+
+	tokens, allowed, err := l.store.TakeToken(ctx, key, rate, l.burst)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Window:    l.period,
+		Burst:     l.burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(l.burst) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res, nil
+}
+
+func (l *limiter) allowSlidingWindow(ctx context.Context, key string) (Result, error) {
+	state, allowed, err := l.store.IncrementWindow(ctx, key, l.limit, l.period)
+	if err != nil {
+		return Result{}, err
+	}
+
+	window := float64(l.period)
+	elapsed := float64(state.Elapsed)
+	previous, current := float64(state.Previous), float64(state.Current)
+
+	count := previous*(window-elapsed)/window + current
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Window:    l.period,
+		Burst:     l.limit,
+		Remaining: max(0, int(math.Floor(float64(l.limit)-count))),
+		// The current window is fully weighted until the end of the next one
+		Reset: time.Duration(window - elapsed),
+	}
+	if current > 0 {
+		res.Reset += l.period
+	}
+
+	if !allowed {
+		// Time until the weighted count drops to limit-1
+		excess := count - float64(l.limit-1)
+		if previous > 0 && excess <= previous*(window-elapsed)/window {
+			res.RetryAfter = time.Duration(math.Ceil(excess * window / previous))
+		} else {
+			// Wait for the next window, where the current count becomes
+			// the previous one
+			res.RetryAfter = time.Duration(window - elapsed)
+			if current > float64(l.limit-1) {
+				res.RetryAfter += time.Duration(math.Ceil(window * (1 - float64(l.limit-1)/current)))
+			}
+		}
+	}
+	return res, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
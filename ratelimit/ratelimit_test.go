@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock starts at the beginning of a minute
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{now: time.Unix(1_700_000_040, 0)}
+	s := NewMemoryStore()
+	s.now = c.Now
+	return s, c
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+	l := NewTokenBucket(10, time.Second, WithStore(store), WithBurst(3))
+
+	for i := range 3 {
+		res, err := l.Allow(ctx, "a")
+		if err != nil || !res.Allowed || res.Remaining != 2-i || res.Burst != 3 {
+			t.Fatalf("request %d: got %+v, %v", i, res, err)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "a")
+	if res.Allowed || res.RetryAfter != 100*time.Millisecond || res.Reset != 300*time.Millisecond {
+		t.Fatalf("exhausted: got %+v", res)
+	}
+
+	// Other keys have their own bucket
+	if res, _ := l.Allow(ctx, "b"); !res.Allowed {
+		t.Fatalf("other key: got %+v", res)
+	}
+
+	clock.now = clock.now.Add(100 * time.Millisecond)
+	if res, _ := l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("refilled: got %+v", res)
+	}
+
+	// The bucket does not exceed its capacity
+	clock.now = clock.now.Add(time.Hour)
+	if res, _ := l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 2 || res.Limit != 10 || res.Window != time.Second {
+		t.Fatalf("full: got %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+	l := NewSlidingWindow(4, time.Minute, WithStore(store))
+
+	for i := range 4 {
+		res, err := l.Allow(ctx, "a")
+		if err != nil || !res.Allowed || res.Remaining != 3-i {
+			t.Fatalf("request %d: got %+v, %v", i, res, err)
+		}
+	}
+
+	// All requests are in the current window
+	res, _ := l.Allow(ctx, "a")
+	if res.Allowed || res.Reset != 2*time.Minute || res.RetryAfter != time.Minute+15*time.Second {
+		t.Fatalf("exhausted: got %+v", res)
+	}
+
+	// Previous window weighted by 3/4: 3 requests counted
+	clock.now = clock.now.Add(75 * time.Second)
+	if res, _ := l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("next window: got %+v", res)
+	}
+	res, _ = l.Allow(ctx, "a")
+	if res.Allowed || res.RetryAfter != 15*time.Second {
+		t.Fatalf("next window exhausted: got %+v", res)
+	}
+
+	clock.now = clock.now.Add(15 * time.Second)
+	if res, _ := l.Allow(ctx, "a"); !res.Allowed {
+		t.Fatalf("after retry: got %+v", res)
+	}
+
+	// Both windows expired
+	clock.now = clock.now.Add(3 * time.Minute)
+	if res, _ := l.Allow(ctx, "a"); !res.Allowed || res.Remaining != 3 {
+		t.Fatalf("expired: got %+v", res)
+	}
+	if len(store.entries) != 1 {
+		t.Fatalf("sweep: got %d entries", len(store.entries))
+	}
+}
+
+func TestInvalidLimits(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore()
+
+	for _, l := range []Limiter{
+		NewTokenBucket(0, 0, WithStore(store), WithName("bucket")),
+		NewSlidingWindow(-1, -time.Second, WithStore(store), WithName("window")),
+	} {
+		res, err := l.Allow(ctx, "a")
+		if err != nil || !res.Allowed || res.Limit != 1 || res.Window != time.Second || res.Reset > 2*time.Second {
+			t.Errorf("first: got %+v, %v", res, err)
+		}
+		res, _ = l.Allow(ctx, "a")
+		if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 2*time.Second {
+			t.Errorf("second: got %+v", res)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ratelimit // import "go.microcore.dev/framework/ratelimit"
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/db/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps the state of limiters in Redis, so it is shared by all
+// instances of a service. Each key is a hash updated by a Lua script using
+// the Redis clock, and expires once its state is back to the initial one.
+type RedisStore struct {
+	manager redis.Manager
+	prefix  string
+}
+
+var (
+	// KEYS[1]: bucket, ARGV[1]: rate per second, ARGV[2]: burst
+	tokenBucketScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+else
+	tokens = math.min(burst, tokens + math.max(0, now - last) / 1000000 * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', string.format('%d', now))
+redis.call('PEXPIRE', KEYS[1], math.max(1, math.ceil((burst - tokens) / rate * 1000)))
+return {allowed, tostring(tokens)}
+`)
+
+	// KEYS[1]: window, ARGV[1]: limit, ARGV[2]: window in microseconds
+	slidingWindowScript = goredis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local current = math.floor(now / window)
+local elapsed = now - current * window
+
+local state = redis.call('HMGET', KEYS[1], 'window', 'previous', 'current')
+local previous = tonumber(state[2]) or 0
+local count = tonumber(state[3]) or 0
+local last = tonumber(state[1])
+if last == current - 1 then
+	previous, count = count, 0
+elseif last ~= current then
+	previous, count = 0, 0
+end
+
+local allowed = 0
+if previous * (window - elapsed) / window + count + 1 <= limit then
+	count = count + 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'window', current, 'previous', previous, 'current', count)
+redis.call('PEXPIRE', KEYS[1], math.ceil((2 * window - elapsed) / 1000))
+return {allowed, previous, count, elapsed}
+`)
+)
+
+func NewRedisStore(manager redis.Manager, opts ...RedisStoreOption) *RedisStore {
+	s := &RedisStore{
+		manager: manager,
+		prefix:  DefaultRedisPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *RedisStore) TakeToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	res, err := tokenBucketScript.Run(
+		ctx,
+		s.manager.Client(),
+		[]string{s.prefix + key},
+		strconv.FormatFloat(rate, 'f', -1, 64),
+		burst,
+	).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+
+	tokens, err := strconv.ParseFloat(res[1].(string), 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return tokens, res[0].(int64) == 1, nil
+}
+
+func (s *RedisStore) IncrementWindow(ctx context.Context, key string, limit int, window time.Duration) (WindowState, bool, error) {
+	res, err := slidingWindowScript.Run(
+		ctx,
+		s.manager.Client(),
+		[]string{s.prefix + key},
+		limit,
+		window.Microseconds(),
+	).Int64Slice()
+	if err != nil {
+		return WindowState{}, false, err
+	}
+
+	return WindowState{
+		Previous: int(res[1]),
+		Current:  int(res[2]),
+		Elapsed:  time.Duration(res[3]) * time.Microsecond,
+	}, res[0] == 1, nil
+}
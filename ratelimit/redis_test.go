@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.microcore.dev/framework/db/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Unix(1_700_000_040, 0))
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	manager := redis.New(redis.WithClient(client), redis.WithoutShutdownHandler())
+	return NewRedisStore(manager, WithRedisPrefix("test:")), mr
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestRedisStore(t)
+	l := NewTokenBucket(10, time.Second, WithStore(store), WithName("api"), WithBurst(3))
+
+	for i := range 3 {
+		res, err := l.Allow(ctx, "a")
+		if err != nil || !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: got %+v, %v", i, res, err)
+		}
+	}
+	if !mr.Exists("test:api:a") {
+		t.Fatalf("expected key test:api:a, got %v", mr.Keys())
+	}
+
+	res, err := l.Allow(ctx, "a")
+	if err != nil || res.Allowed || res.RetryAfter != 100*time.Millisecond {
+		t.Fatalf("exhausted: got %+v, %v", res, err)
+	}
+
+	mr.SetTime(time.Unix(1_700_000_040, int64(100*time.Millisecond)))
+	if res, err := l.Allow(ctx, "a"); err != nil || !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("refilled: got %+v, %v", res, err)
+	}
+}
+
+func TestRedisSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestRedisStore(t)
+	l := NewSlidingWindow(4, time.Minute, WithStore(store), WithName("login"))
+
+	for i := range 4 {
+		res, err := l.Allow(ctx, "a")
+		if err != nil || !res.Allowed || res.Remaining != 3-i {
+			t.Fatalf("request %d: got %+v, %v", i, res, err)
+		}
+	}
+	res, err := l.Allow(ctx, "a")
+	if err != nil || res.Allowed || res.RetryAfter != time.Minute+15*time.Second {
+		t.Fatalf("exhausted: got %+v, %v", res, err)
+	}
+
+	// Previous window weighted by 3/4: 3 requests counted
+	mr.SetTime(time.Unix(1_700_000_040+75, 0))
+	if res, err := l.Allow(ctx, "a"); err != nil || !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("next window: got %+v, %v", res, err)
+	}
+	if res, _ := l.Allow(ctx, "a"); res.Allowed || res.RetryAfter != 15*time.Second {
+		t.Fatalf("next window exhausted: got %+v", res)
+	}
+
+	// Unreachable stores fail
+	mr.Close()
+	if _, err := l.Allow(ctx, "a"); err == nil {
+		t.Fatal("expected error with unreachable store")
+	}
+}
@@ -25,6 +25,11 @@ func (e *ExitReason) Unwrap() error {
 	return e.Err
 }
 
+// ExitCode returns the exit code carried by the reason.
+func (e *ExitReason) ExitCode() int {
+	return e.Code
+}
+
 // NewExitReason creates a new program termination reason with the specified exit code.
 //
 // ExitReason represents a reason for program termination. It can wrap underlying errors (if provided)
@@ -57,6 +57,7 @@ Key points:
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -116,7 +117,7 @@ func newManager() Manager {
 		exit:     make(chan int, 1),
 		code:     make(chan int, 1),
 		catch:    make(chan os.Signal, 1),
-		handlers: []Handler{},
+		handlers: []Handler{closeLogs},
 	}
 	m.state.Store(int32(stateInit))
 	go m.subscribe()
@@ -245,7 +246,7 @@ func (m *manager) exec(code int) bool {
 			if err := fn(ctx, code); err != nil {
 				logger.Error(
 					"error in handler",
-					slog.Any("error", err),
+					log.Err(err),
 				)
 				success.Store(false)
 			}
@@ -281,6 +282,12 @@ func (m *manager) term(code int) {
 			"exit",
 			slog.Int("code", code),
 		)
+		// Write the records retained for post-mortem analysis
+		if code != ExitOK {
+			if err := log.DumpCrashBuffer(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to dump crash buffer: %v\n", err)
+			}
+		}
 		os.Stdout.Sync()
 		os.Stderr.Sync()
 		m.exit <- code
@@ -289,6 +296,12 @@ func (m *manager) term(code int) {
 	})
 }
 
+// closeLogs writes the records queued in asynchronous log handlers, which
+// then write synchronously until the process exits.
+func closeLogs(ctx context.Context, _ int) error {
+	return log.CloseAsync(ctx)
+}
+
 func def() Manager {
 	once.Do(func() {
 		defaultManager = newManager()
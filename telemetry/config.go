@@ -4,7 +4,7 @@ import (
 	"time"
 
 	_ "go.microcore.dev/framework"
-	"go.opentelemetry.io/otel/propagation"
+	"go.microcore.dev/framework/telemetry/propagator"
 )
 
 const (
@@ -13,6 +13,7 @@ const (
 	DefaultShutdownTimeout = 10 * time.Second
 	DefaultShutdownHandler = true
 	DefaultSetLogProvider  = true
+	DefaultRuntimeMetrics  = false
 
 	DefaultMetricPeriodicReaderInterval = 30 * time.Second
 	DefaultMetricPeriodicReaderTimeout  = 10 * time.Second
@@ -22,5 +23,5 @@ const (
 )
 
 var (
-	defaultPropagator = &propagation.TraceContext{}
+	defaultPropagator = propagator.New()
 )
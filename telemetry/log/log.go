@@ -1,6 +1,9 @@
 package log // import "go.microcore.dev/framework/telemetry/log"
 
 import (
+	"context"
+	"log/slog"
+
 	_ "go.microcore.dev/framework"
 	"go.microcore.dev/framework/log"
 
@@ -9,17 +12,51 @@ import (
 	logSdk "go.opentelemetry.io/otel/sdk/log"
 )
 
-type severity struct{}
+type (
+	// severity reports the lowest level enabled for any package, so that
+	// Enabled lets through everything a per-package override may need.
+	severity struct{}
+
+	// packageProcessor drops records below the level of the package they
+	// were logged from (see log.GetPackageLevel).
+	packageProcessor struct {
+		logSdk.Processor
+	}
+)
 
 func (s severity) Severity() otelLog.Severity {
-	var sev minsev.Severity
-	_ = sev.UnmarshalText([]byte(log.GetLevel().String()))
-	return sev.Severity()
+	return toSeverity(log.GetMinLevel())
 }
 
+func (p packageProcessor) OnEmit(ctx context.Context, record *logSdk.Record) error {
+	var pkg string
+	record.WalkAttributes(func(kv otelLog.KeyValue) bool {
+		if kv.Key == log.PackageKey && kv.Value.Kind() == otelLog.KindString {
+			pkg = kv.Value.AsString()
+			return false
+		}
+		return true
+	})
+	if record.Severity() < toSeverity(log.GetPackageLevel(pkg)) {
+		return nil
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
+
+// NewProcessor wraps processor so that only records allowed by the global
+// and per-package log levels are emitted.
 func NewProcessor(processor logSdk.Processor) *minsev.LogProcessor {
+	if processor != nil {
+		processor = packageProcessor{processor}
+	}
 	return minsev.NewLogProcessor(
 		processor,
 		severity{},
 	)
 }
+
+func toSeverity(l slog.Level) otelLog.Severity {
+	var sev minsev.Severity
+	_ = sev.UnmarshalText([]byte(l.String()))
+	return sev.Severity()
+}
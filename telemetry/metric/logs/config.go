@@ -0,0 +1,11 @@
+package logs // import "go.microcore.dev/framework/telemetry/metric/logs"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/telemetry/metric/logs"
+
+	InstrumentationName = "go.microcore.dev/framework/telemetry/metric/logs"
+)
@@ -0,0 +1,61 @@
+package logs // import "go.microcore.dev/framework/telemetry/metric/logs"
+
+import (
+	"context"
+	"fmt"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var logger = log.New(pkg)
+
+// Start registers instrumentation of the log package on the given
+// MeterProvider.
+//
+// Metrics emitted:
+//
+//	log.records.dropped    {record}    Records dropped by log handlers, by reason.
+//	log.queue.depth        {record}    Records waiting in asynchronous log handlers.
+func Start(provider metric.MeterProvider) error {
+	meter := provider.Meter(InstrumentationName)
+
+	dropped, err := meter.Int64ObservableCounter(
+		"log.records.dropped",
+		metric.WithUnit("{record}"),
+		metric.WithDescription("Number of log records dropped before reaching an output."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create log.records.dropped: %w", err)
+	}
+
+	queueDepth, err := meter.Int64ObservableUpDownCounter(
+		"log.queue.depth",
+		metric.WithUnit("{record}"),
+		metric.WithDescription("Number of log records waiting to be written by asynchronous handlers."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create log.queue.depth: %w", err)
+	}
+
+	if _, err := meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			for reason, n := range log.DroppedRecords() {
+				o.ObserveInt64(dropped, n, metric.WithAttributes(attribute.String("reason", reason)))
+			}
+			o.ObserveInt64(queueDepth, int64(log.QueueLen()))
+			return nil
+		},
+		dropped,
+		queueDepth,
+	); err != nil {
+		return fmt.Errorf("failed to register logs callback: %w", err)
+	}
+
+	logger.Debug("logs instrumentation started")
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package process // import "go.microcore.dev/framework/telemetry/metric/process"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/telemetry/metric/process"
+
+	InstrumentationName = "go.microcore.dev/framework/telemetry/metric/process"
+)
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package process // import "go.microcore.dev/framework/telemetry/metric/process"
+
+// cpuTimes is not implemented on this platform.
+func cpuTimes() (user float64, system float64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package process // import "go.microcore.dev/framework/telemetry/metric/process"
+
+import (
+	"syscall"
+)
+
+// cpuTimes returns user and system CPU time of the process in seconds.
+func cpuTimes() (user float64, system float64, ok bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0, 0, false
+	}
+	return timevalSeconds(ru.Utime), timevalSeconds(ru.Stime), true
+}
+
+func timevalSeconds(tv syscall.Timeval) float64 {
+	return float64(tv.Sec) + float64(tv.Usec)/1e6
+}
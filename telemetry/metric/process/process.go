@@ -0,0 +1,153 @@
+package process // import "go.microcore.dev/framework/telemetry/metric/process"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	logger = log.New(pkg)
+
+	startTime = time.Now()
+
+	cpuModeUser   = metric.WithAttributes(attribute.String("cpu.mode", "user"))
+	cpuModeSystem = metric.WithAttributes(attribute.String("cpu.mode", "system"))
+)
+
+// Start registers process instrumentation on the given MeterProvider.
+//
+// Metrics emitted:
+//
+//	process.cpu.time                       s                  Total CPU seconds broken down by cpu.mode.
+//	process.memory.usage                   By                 Resident set size of the process.
+//	process.memory.virtual                 By                 Virtual memory size of the process.
+//	process.unix.file_descriptor.count     {file_descriptor}  Number of open file descriptors.
+//	process.uptime                         s                  Time since the process started.
+//
+// Memory and file descriptor metrics are read from /proc and are only
+// reported on systems that provide it.
+func Start(provider metric.MeterProvider) error {
+	meter := provider.Meter(InstrumentationName)
+
+	cpuTime, err := meter.Float64ObservableCounter(
+		"process.cpu.time",
+		metric.WithUnit("s"),
+		metric.WithDescription("Total CPU seconds broken down by different CPU modes."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.cpu.time: %w", err)
+	}
+
+	memoryUsage, err := meter.Int64ObservableUpDownCounter(
+		"process.memory.usage",
+		metric.WithUnit("By"),
+		metric.WithDescription("The amount of physical memory in use."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.memory.usage: %w", err)
+	}
+
+	memoryVirtual, err := meter.Int64ObservableUpDownCounter(
+		"process.memory.virtual",
+		metric.WithUnit("By"),
+		metric.WithDescription("The amount of committed virtual memory."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.memory.virtual: %w", err)
+	}
+
+	fdCount, err := meter.Int64ObservableUpDownCounter(
+		"process.unix.file_descriptor.count",
+		metric.WithUnit("{file_descriptor}"),
+		metric.WithDescription("Number of unix file descriptors in use by the process."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.unix.file_descriptor.count: %w", err)
+	}
+
+	uptime, err := meter.Float64ObservableGauge(
+		"process.uptime",
+		metric.WithUnit("s"),
+		metric.WithDescription("The time the process has been running."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.uptime: %w", err)
+	}
+
+	if _, err := meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			if user, system, ok := cpuTimes(); ok {
+				o.ObserveFloat64(cpuTime, user, cpuModeUser)
+				o.ObserveFloat64(cpuTime, system, cpuModeSystem)
+			}
+			if rss, vms, ok := memory(); ok {
+				o.ObserveInt64(memoryUsage, rss)
+				o.ObserveInt64(memoryVirtual, vms)
+			}
+			if n, ok := openFDs(); ok {
+				o.ObserveInt64(fdCount, n)
+			}
+			o.ObserveFloat64(uptime, time.Since(startTime).Seconds())
+			return nil
+		},
+		cpuTime,
+		memoryUsage,
+		memoryVirtual,
+		fdCount,
+		uptime,
+	); err != nil {
+		return fmt.Errorf("failed to register process callback: %w", err)
+	}
+
+	logger.Debug("process instrumentation started")
+
+	return nil
+}
+
+// memory returns the resident and virtual memory size in bytes
+// from /proc/self/statm.
+func memory() (rss int64, vms int64, ok bool) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, 0, false
+	}
+	fields := bytes.Fields(data)
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	resident, err := strconv.ParseInt(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	page := int64(os.Getpagesize())
+	return resident * page, size * page, true
+}
+
+// openFDs returns the number of entries in /proc/self/fd.
+func openFDs() (int64, bool) {
+	f, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return 0, false
+	}
+	// Exclude the descriptor opened to read the directory itself.
+	return int64(len(names) - 1), true
+}
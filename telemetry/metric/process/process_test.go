@@ -0,0 +1,56 @@
+package process
+
+import (
+	"context"
+	goRuntime "runtime"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStart(t *testing.T) {
+	reader := metricSdk.NewManualReader()
+	if err := Start(metricSdk.NewMeterProvider(metricSdk.WithReader(reader))); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+
+	want := []string{"process.uptime"}
+	if goRuntime.GOOS == "linux" {
+		want = append(want, "process.cpu.time", "process.memory.usage", "process.memory.virtual", "process.unix.file_descriptor.count")
+	}
+	for _, name := range want {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s not reported", name)
+		}
+	}
+
+	if cpu, ok := got["process.cpu.time"].(metricdata.Sum[float64]); ok {
+		modes := map[string]bool{}
+		for _, dp := range cpu.DataPoints {
+			mode, _ := dp.Attributes.Value(attribute.Key("cpu.mode"))
+			modes[mode.AsString()] = true
+		}
+		if !modes["user"] || !modes["system"] {
+			t.Errorf("process.cpu.time: got modes %v", modes)
+		}
+	}
+	if rss, ok := got["process.memory.usage"].(metricdata.Sum[int64]); ok && rss.DataPoints[0].Value <= 0 {
+		t.Errorf("process.memory.usage: got %d", rss.DataPoints[0].Value)
+	}
+	if fds, ok := got["process.unix.file_descriptor.count"].(metricdata.Sum[int64]); ok && fds.DataPoints[0].Value < 3 {
+		t.Errorf("process.unix.file_descriptor.count: got %d", fds.DataPoints[0].Value)
+	}
+}
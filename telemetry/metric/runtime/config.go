@@ -0,0 +1,20 @@
+package runtime // import "go.microcore.dev/framework/telemetry/metric/runtime"
+
+import (
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/telemetry/metric/runtime"
+
+	InstrumentationName = "go.microcore.dev/framework/telemetry/metric/runtime"
+
+	DefaultMinimumReadInterval = 15 * time.Second
+)
+
+var (
+	// Quantiles reported for the scheduler latency gauge.
+	defaultScheduleQuantiles = []float64{0.5, 0.9, 0.99}
+)
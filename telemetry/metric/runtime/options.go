@@ -0,0 +1,20 @@
+package runtime // import "go.microcore.dev/framework/telemetry/metric/runtime"
+
+import (
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+type Option func(*config)
+
+// WithMinimumReadInterval sets the minimum interval between reads of the Go
+// runtime statistics. Collections happening more often than this reuse the
+// previously read values.
+//
+// By default, if this option is not used, DefaultMinimumReadInterval is used.
+func WithMinimumReadInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.minimumReadInterval = d
+	}
+}
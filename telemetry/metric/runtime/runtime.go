@@ -0,0 +1,236 @@
+package runtime // import "go.microcore.dev/framework/telemetry/metric/runtime"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type (
+	config struct {
+		minimumReadInterval time.Duration
+	}
+
+	collector struct {
+		mu                  sync.Mutex
+		minimumReadInterval time.Duration
+		lastRead            time.Time
+		samples             []metrics.Sample
+		gcCycles            int64
+		gcPauses            float64
+		schedule            []uint64
+		scheduleQuantiles   map[float64]float64
+	}
+)
+
+const (
+	gcCycles      = "/gc/cycles/total:gc-cycles"
+	gcPauses      = "/sched/pauses/total/gc:seconds"
+	scheduleDelay = "/sched/latencies:seconds"
+)
+
+var logger = log.New(pkg)
+
+// Start registers Go runtime instrumentation on the given MeterProvider.
+//
+// The standard OpenTelemetry runtime metrics (go.memory.*, go.goroutine.count,
+// go.processor.limit, go.config.gogc) are complemented with:
+//
+//	go.gc.count              {gc_cycle}  Count of completed GC cycles.
+//	go.gc.pause.duration     s           Estimated total time spent in GC stop-the-world pauses.
+//	go.schedule.latency      s           Quantiles of the time goroutines spent runnable
+//	                                     before running, over the last read interval.
+//
+// The runtime only reports GC pauses as a histogram, so go.gc.pause.duration
+// is estimated from bucket midpoints and may deviate from the exact total by
+// up to the bucket width of each pause.
+func Start(provider metric.MeterProvider, opts ...Option) error {
+	c := &config{
+		minimumReadInterval: DefaultMinimumReadInterval,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := runtime.Start(
+		runtime.WithMeterProvider(provider),
+		runtime.WithMinimumReadMemStatsInterval(c.minimumReadInterval),
+	); err != nil {
+		return fmt.Errorf("failed to start runtime instrumentation: %w", err)
+	}
+
+	meter := provider.Meter(InstrumentationName)
+
+	gcCount, err := meter.Int64ObservableCounter(
+		"go.gc.count",
+		metric.WithUnit("{gc_cycle}"),
+		metric.WithDescription("Count of completed GC cycles."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create go.gc.count: %w", err)
+	}
+
+	gcPause, err := meter.Float64ObservableCounter(
+		"go.gc.pause.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Estimated total time spent in GC stop-the-world pauses."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create go.gc.pause.duration: %w", err)
+	}
+
+	scheduleLatency, err := meter.Float64ObservableGauge(
+		"go.schedule.latency",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time goroutines spent runnable before running, over the last read interval."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create go.schedule.latency: %w", err)
+	}
+
+	col := newCollector(c.minimumReadInterval)
+
+	if _, err := meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			col.mu.Lock()
+			defer col.mu.Unlock()
+
+			col.refresh()
+
+			o.ObserveInt64(gcCount, col.gcCycles)
+			o.ObserveFloat64(gcPause, col.gcPauses)
+			for q, v := range col.scheduleQuantiles {
+				o.ObserveFloat64(
+					scheduleLatency,
+					v,
+					metric.WithAttributes(attribute.Float64("quantile", q)),
+				)
+			}
+			return nil
+		},
+		gcCount,
+		gcPause,
+		scheduleLatency,
+	); err != nil {
+		return fmt.Errorf("failed to register runtime callback: %w", err)
+	}
+
+	logger.Debug(
+		"runtime instrumentation started",
+		slog.Duration("minimum_read_interval", c.minimumReadInterval),
+	)
+
+	return nil
+}
+
+func newCollector(minimumReadInterval time.Duration) *collector {
+	return &collector{
+		minimumReadInterval: minimumReadInterval,
+		samples: []metrics.Sample{
+			{Name: gcCycles},
+			{Name: gcPauses},
+			{Name: scheduleDelay},
+		},
+		scheduleQuantiles: map[float64]float64{},
+	}
+}
+
+// refresh reads runtime metrics unless they were read less than
+// minimumReadInterval ago. The caller must hold c.mu.
+func (c *collector) refresh() {
+	now := time.Now()
+	if !c.lastRead.IsZero() && now.Sub(c.lastRead) < c.minimumReadInterval {
+		return
+	}
+	c.lastRead = now
+
+	metrics.Read(c.samples)
+
+	for _, s := range c.samples {
+		switch s.Name {
+		case gcCycles:
+			if s.Value.Kind() == metrics.KindUint64 {
+				c.gcCycles = int64(s.Value.Uint64())
+			}
+		case gcPauses:
+			if s.Value.Kind() == metrics.KindFloat64Histogram {
+				c.gcPauses = histogramSum(s.Value.Float64Histogram())
+			}
+		case scheduleDelay:
+			if s.Value.Kind() == metrics.KindFloat64Histogram {
+				c.refreshSchedule(s.Value.Float64Histogram())
+			}
+		}
+	}
+}
+
+// refreshSchedule computes quantiles over the scheduler latency observed since
+// the previous read, as the runtime histogram is cumulative.
+func (c *collector) refreshSchedule(h *metrics.Float64Histogram) {
+	delta := make([]uint64, len(h.Counts))
+	var total uint64
+	for i, n := range h.Counts {
+		if i < len(c.schedule) {
+			n -= c.schedule[i]
+		}
+		delta[i] = n
+		total += n
+	}
+	c.schedule = append(c.schedule[:0], h.Counts...)
+
+	clear(c.scheduleQuantiles)
+	if total == 0 {
+		return
+	}
+
+	for _, q := range defaultScheduleQuantiles {
+		rank := uint64(math.Ceil(q * float64(total)))
+		var cumulative uint64
+		for i, n := range delta {
+			cumulative += n
+			if cumulative >= rank {
+				c.scheduleQuantiles[q] = bucketValue(h.Buckets, i)
+				break
+			}
+		}
+	}
+}
+
+// histogramSum estimates the sum of all samples in a runtime histogram using
+// bucket midpoints.
+func histogramSum(h *metrics.Float64Histogram) float64 {
+	var sum float64
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		sum += float64(n) * bucketValue(h.Buckets, i)
+	}
+	return sum
+}
+
+// bucketValue returns a representative value for bucket i, falling back to
+// the finite boundary for the open-ended first and last buckets.
+func bucketValue(buckets []float64, i int) float64 {
+	lo, hi := buckets[i], buckets[i+1]
+	switch {
+	case math.IsInf(lo, -1):
+		return hi
+	case math.IsInf(hi, 1):
+		return lo
+	default:
+		return (lo + hi) / 2
+	}
+}
@@ -0,0 +1,80 @@
+package runtime
+
+import (
+	"context"
+	"math"
+	goRuntime "runtime"
+	"runtime/metrics"
+	"testing"
+
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func collect(t *testing.T, reader *metricSdk.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+	return got
+}
+
+func TestStart(t *testing.T) {
+	reader := metricSdk.NewManualReader()
+	if err := Start(metricSdk.NewMeterProvider(metricSdk.WithReader(reader)), WithMinimumReadInterval(0)); err != nil {
+		t.Fatal(err)
+	}
+	goRuntime.GC()
+
+	got := collect(t, reader)
+	for _, name := range []string{"go.gc.count", "go.gc.pause.duration", "go.goroutine.count", "go.memory.used"} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s not reported", name)
+		}
+	}
+	if gc, ok := got["go.gc.count"].(metricdata.Sum[int64]); !ok || gc.DataPoints[0].Value < 1 {
+		t.Errorf("go.gc.count: got %+v", got["go.gc.count"])
+	}
+	if pause, ok := got["go.gc.pause.duration"].(metricdata.Sum[float64]); !ok || pause.DataPoints[0].Value <= 0 {
+		t.Errorf("go.gc.pause.duration: got %+v", got["go.gc.pause.duration"])
+	}
+}
+
+func TestHistogramSum(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{1, 2, 0, 3},
+		Buckets: []float64{math.Inf(-1), 1, 2, 4, math.Inf(1)},
+	}
+	// 1*1 + 2*1.5 + 3*4
+	if got := histogramSum(h); got != 16 {
+		t.Errorf("got %v, want 16", got)
+	}
+}
+
+func TestRefreshSchedule(t *testing.T) {
+	c := newCollector(0)
+	buckets := []float64{0, 1, 2, 3}
+
+	c.refreshSchedule(&metrics.Float64Histogram{Counts: []uint64{10, 0, 0}, Buckets: buckets})
+	if got := c.scheduleQuantiles[0.99]; got != 0.5 {
+		t.Errorf("first read: got p99 %v, want 0.5", got)
+	}
+
+	// Only samples recorded since the previous read are considered
+	c.refreshSchedule(&metrics.Float64Histogram{Counts: []uint64{10, 0, 4}, Buckets: buckets})
+	if got := c.scheduleQuantiles[0.5]; got != 2.5 {
+		t.Errorf("second read: got p50 %v, want 2.5", got)
+	}
+
+	c.refreshSchedule(&metrics.Float64Histogram{Counts: []uint64{10, 0, 4}, Buckets: buckets})
+	if len(c.scheduleQuantiles) != 0 {
+		t.Errorf("no new samples: got %v", c.scheduleQuantiles)
+	}
+}
@@ -373,6 +373,50 @@ func (_c *MockManager_GetPropagator_Call) RunAndReturn(run func() propagation.Te
 	return _c
 }
 
+// GetRuntimeMetrics provides a mock function for the type MockManager
+func (_mock *MockManager) GetRuntimeMetrics() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRuntimeMetrics")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockManager_GetRuntimeMetrics_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRuntimeMetrics'
+type MockManager_GetRuntimeMetrics_Call struct {
+	*mock.Call
+}
+
+// GetRuntimeMetrics is a helper method to define mock.On call
+func (_e *MockManager_Expecter) GetRuntimeMetrics() *MockManager_GetRuntimeMetrics_Call {
+	return &MockManager_GetRuntimeMetrics_Call{Call: _e.mock.On("GetRuntimeMetrics")}
+}
+
+func (_c *MockManager_GetRuntimeMetrics_Call) Run(run func()) *MockManager_GetRuntimeMetrics_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockManager_GetRuntimeMetrics_Call) Return(b bool) *MockManager_GetRuntimeMetrics_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *MockManager_GetRuntimeMetrics_Call) RunAndReturn(run func() bool) *MockManager_GetRuntimeMetrics_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetSetLogProvider provides a mock function for the type MockManager
 func (_mock *MockManager) GetSetLogProvider() bool {
 	ret := _mock.Called()
@@ -6,6 +6,8 @@ import (
 	_ "go.microcore.dev/framework"
 	logProvider "go.microcore.dev/framework/telemetry/log/provider"
 	metricProvider "go.microcore.dev/framework/telemetry/metric/provider"
+	metricRuntime "go.microcore.dev/framework/telemetry/metric/runtime"
+	"go.microcore.dev/framework/telemetry/propagator"
 	traceProvider "go.microcore.dev/framework/telemetry/trace/provider"
 
 	"go.opentelemetry.io/otel/propagation"
@@ -58,6 +60,12 @@ func WithPropagator(propagator propagation.TextMapPropagator) Option {
 	}
 }
 
+func WithPropagatorOptions(opts ...propagator.Option) Option {
+	return func(t *t) {
+		t.propagator = propagator.New(opts...)
+	}
+}
+
 func WithShutdownTimeout(timeout time.Duration) Option {
 	return func(t *t) {
 		t.shutdownTimeout = timeout
@@ -70,8 +78,20 @@ func WithoutShutdownHandler() Option {
 	}
 }
 
+// WithoutSetLogProvider disables exporting records of the global logger
+// through the telemetry log provider (see log.AddSink).
 func WithoutSetLogProvider() Option {
 	return func(t *t) {
 		t.setLogProvider = false
 	}
 }
+
+// WithRuntimeMetrics registers Go runtime (GC, heap, goroutines, scheduler
+// latency) and process (CPU, memory, file descriptors) instrumentation on the
+// MeterProvider.
+func WithRuntimeMetrics(opts ...metricRuntime.Option) Option {
+	return func(t *t) {
+		t.runtimeMetrics = true
+		t.runtimeMetricsOptions = opts
+	}
+}
@@ -0,0 +1,9 @@
+package propagator // import "go.microcore.dev/framework/telemetry/propagator"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/telemetry/propagator"
+)
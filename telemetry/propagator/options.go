@@ -0,0 +1,42 @@
+package propagator // import "go.microcore.dev/framework/telemetry/propagator"
+
+import (
+	_ "go.microcore.dev/framework"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type Option func(*[]propagation.TextMapPropagator)
+
+// WithB3 adds the Zipkin B3 propagator using the single "b3" header for
+// injection. Extraction accepts both single and multiple header encodings.
+func WithB3() Option {
+	return func(o *[]propagation.TextMapPropagator) {
+		*o = append(*o, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+	}
+}
+
+// WithB3Multi adds the Zipkin B3 propagator using the multiple "x-b3-*"
+// headers for injection. Extraction accepts both single and multiple header
+// encodings.
+func WithB3Multi() Option {
+	return func(o *[]propagation.TextMapPropagator) {
+		*o = append(*o, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+	}
+}
+
+// WithJaeger adds the Jaeger propagator using the "uber-trace-id" header.
+func WithJaeger() Option {
+	return func(o *[]propagation.TextMapPropagator) {
+		*o = append(*o, jaeger.Jaeger{})
+	}
+}
+
+// WithPropagator adds any custom propagator to the composite.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(o *[]propagation.TextMapPropagator) {
+		*o = append(*o, propagator)
+	}
+}
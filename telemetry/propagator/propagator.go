@@ -0,0 +1,31 @@
+package propagator // import "go.microcore.dev/framework/telemetry/propagator"
+
+import (
+	"log/slog"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var logger = log.New(pkg)
+
+// New creates a composite propagator. W3C TraceContext and W3C Baggage are
+// always included; additional formats are appended in the order of the
+// given options, so on extraction the last matching format wins.
+func New(opts ...Option) propagation.TextMapPropagator {
+	propagators := []propagation.TextMapPropagator{
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	}
+	for _, opt := range opts {
+		opt(&propagators)
+	}
+	propagator := propagation.NewCompositeTextMapPropagator(propagators...)
+	logger.Debug(
+		"propagator created",
+		slog.Any("fields", propagator.Fields()),
+	)
+	return propagator
+}
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -29,6 +31,10 @@ import (
 
 	metricPeriodicReader "go.microcore.dev/framework/telemetry/metric/reader/periodic"
 
+	metricLogs "go.microcore.dev/framework/telemetry/metric/logs"
+	metricProcess "go.microcore.dev/framework/telemetry/metric/process"
+	metricRuntime "go.microcore.dev/framework/telemetry/metric/runtime"
+
 	otelLog "go.opentelemetry.io/otel/log"
 	otelMetric "go.opentelemetry.io/otel/metric"
 	otelTrace "go.opentelemetry.io/otel/trace"
@@ -56,6 +62,7 @@ type (
 		GetShutdownTimeout() time.Duration
 		GetShutdownHandler() bool
 		GetSetLogProvider() bool
+		GetRuntimeMetrics() bool
 		ForceFlush(ctx context.Context) error
 		Shutdown(ctx context.Context, code int) error
 	}
@@ -68,10 +75,19 @@ type (
 		shutdownTimeout time.Duration
 		shutdownHandler bool
 		setLogProvider  bool
+		logSink         string
+		runtimeMetrics  bool
+
+		runtimeMetricsOptions []metricRuntime.Option
 	}
 )
 
-var logger = log.New(pkg)
+var (
+	logger = log.New(pkg)
+
+	// Numbers the log sinks, so that managers do not replace each other's
+	managers atomic.Uint64
+)
 
 func New(opts ...Option) Manager {
 	t := &t{
@@ -79,6 +95,7 @@ func New(opts ...Option) Manager {
 		shutdownTimeout: DefaultShutdownTimeout,
 		shutdownHandler: DefaultShutdownHandler,
 		setLogProvider:  DefaultSetLogProvider,
+		runtimeMetrics:  DefaultRuntimeMetrics,
 	}
 
 	for _, opt := range opts {
@@ -97,17 +114,42 @@ func New(opts ...Option) Manager {
 		t.logProvider = logProvider.New()
 	}
 
+	if err := metricLogs.Start(t.metricProvider); err != nil {
+		logger.Error(
+			"failed to start logs metrics",
+			log.Err(err),
+		)
+	}
+
+	if t.runtimeMetrics {
+		if err := metricRuntime.Start(t.metricProvider, t.runtimeMetricsOptions...); err != nil {
+			logger.Error(
+				"failed to start runtime metrics",
+				log.Err(err),
+			)
+		}
+		if err := metricProcess.Start(t.metricProvider); err != nil {
+			logger.Error(
+				"failed to start process metrics",
+				log.Err(err),
+			)
+		}
+	}
+
 	if t.shutdownHandler {
 		shutdown.AddHandler(t.Shutdown)
 		logger.Debug("shutdown handler registered")
 	}
 
 	if t.setLogProvider {
+		t.logSink = logProvider.InstrumentationName + "#" + strconv.FormatUint(managers.Add(1), 10)
 		logger.Info(
-			"switching logger backend to telemetry",
+			"adding telemetry log sink",
 			slog.String("provider", logProvider.InstrumentationName),
+			slog.String("sink", t.logSink),
 		)
-		log.SetBackend(
+		log.AddSink(
+			t.logSink,
 			otelslog.NewHandler(
 				logProvider.InstrumentationName,
 				otelslog.WithLoggerProvider(t.logProvider),
@@ -118,7 +160,10 @@ func New(opts ...Option) Manager {
 	return t
 }
 
-func NewDefaultInsecureOtlpGrpc(ctx context.Context, endpoint string, service string) (Manager, error) {
+// NewDefaultInsecureOtlpGrpc returns a Manager exporting traces, metrics and
+// logs over insecure OTLP gRPC to endpoint. Options, such as
+// WithRuntimeMetrics, are applied after the defaults.
+func NewDefaultInsecureOtlpGrpc(ctx context.Context, endpoint string, service string, opts ...Option) (Manager, error) {
 	host, err := os.Hostname()
 	if err != nil {
 		host = "undefined"
@@ -154,7 +199,7 @@ func NewDefaultInsecureOtlpGrpc(ctx context.Context, endpoint string, service st
 		return nil, err
 	}
 
-	return New(
+	return New(append([]Option{
 		WithTraceProviderOptions(
 			traceProvider.WithBatcher(
 				otlpTraceGrpcExporter,
@@ -221,7 +266,7 @@ func NewDefaultInsecureOtlpGrpc(ctx context.Context, endpoint string, service st
 				),
 			),
 		),
-	), nil
+	}, opts...)...), nil
 }
 
 func (t *t) GetTraceProvider() *traceSdk.TracerProvider {
@@ -268,6 +313,10 @@ func (t *t) GetSetLogProvider() bool {
 	return t.setLogProvider
 }
 
+func (t *t) GetRuntimeMetrics() bool {
+	return t.runtimeMetrics
+}
+
 func (t *t) ForceFlush(ctx context.Context) error {
 	logger.Debug("force flush")
 
@@ -292,6 +341,11 @@ func (t *t) Shutdown(ctx context.Context, code int) error {
 		slog.Int("code", code),
 	)
 
+	// Stop exporting logs before the provider goes away
+	if t.setLogProvider {
+		log.RemoveSink(t.logSink)
+	}
+
 	providers := []struct {
 		name string
 		fn   func(context.Context) error
@@ -0,0 +1,47 @@
+package telemetrytest // import "go.microcore.dev/framework/telemetry/telemetrytest"
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	_ "go.microcore.dev/framework"
+
+	logSdk "go.opentelemetry.io/otel/sdk/log"
+)
+
+// logExporter is an in-memory log exporter. The SDK does not provide one,
+// unlike tracetest.InMemoryExporter for spans.
+type logExporter struct {
+	mu      sync.Mutex
+	records []logSdk.Record
+}
+
+func (e *logExporter) Export(ctx context.Context, records []logSdk.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *logExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (e *logExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (e *logExporter) get() []logSdk.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.records)
+}
+
+func (e *logExporter) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = nil
+}
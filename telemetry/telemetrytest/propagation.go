@@ -0,0 +1,63 @@
+package telemetrytest // import "go.microcore.dev/framework/telemetry/telemetrytest"
+
+import (
+	"context"
+	"testing"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/telemetry/propagator"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AssertPropagation fails the test unless a sampled span context and baggage
+// survive a round trip through a carrier with the B3 single header, B3
+// multiple header and Jaeger formats.
+//
+// roundTrip creates the outgoing carrier, calls inject with it, and returns
+// the carrier the receiving side extracts from, for example after writing
+// and reading the headers as on the wire. The W3C traceparent header is
+// blanked after injection, so that only the tested format carries the span
+// context.
+func AssertPropagation(t testing.TB, roundTrip func(inject func(propagation.TextMapCarrier)) propagation.TextMapCarrier) {
+	t.Helper()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(trace.ContextWithSpanContext(context.Background(), sc), bag)
+
+	for name, tt := range map[string]struct {
+		opt    propagator.Option
+		header string
+	}{
+		"b3":       {propagator.WithB3(), "b3"},
+		"b3 multi": {propagator.WithB3Multi(), "x-b3-traceid"},
+		"jaeger":   {propagator.WithJaeger(), "uber-trace-id"},
+	} {
+		p := propagator.New(tt.opt)
+
+		in := roundTrip(func(carrier propagation.TextMapCarrier) {
+			p.Inject(ctx, carrier)
+			if carrier.Get(tt.header) == "" {
+				t.Errorf("%s: %s not injected", name, tt.header)
+			}
+			carrier.Set("traceparent", "")
+		})
+
+		got := p.Extract(context.Background(), in)
+		if gotSc := trace.SpanContextFromContext(got); gotSc.TraceID() != sc.TraceID() || gotSc.SpanID() != sc.SpanID() || !gotSc.IsSampled() {
+			t.Errorf("%s: got span context %+v", name, gotSc)
+		}
+		if v := baggage.FromContext(got).Member("tenant").Value(); v != "acme" {
+			t.Errorf("%s: got baggage %q", name, v)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package telemetrytest // import "go.microcore.dev/framework/telemetry/telemetrytest"
+
+/*
+Package telemetrytest provides a telemetry.Manager backed by in-memory span,
+metric and log exporters, together with helpers to assert what was recorded.
+
+The returned Recorder implements telemetry.Manager, so it can be passed to any
+framework integration that accepts one (HTTP server and client, Kafka,
+Postgres, Redis):
+
+	func TestHandler(t *testing.T) {
+	    tm := telemetrytest.New(t)
+	    srv, _ := server.New(server.WithTelemetryManager(tm))
+	    // ... issue a request
+	    tm.AssertSpan("incoming http request", attribute.Int("status", 200))
+	}
+
+Spans and logs are exported synchronously, so they are visible as soon as the
+span has ended or the record has been emitted. Metrics are collected on demand
+by MetricValue.
+*/
+
+import (
+	"context"
+	"testing"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/telemetry"
+	logProvider "go.microcore.dev/framework/telemetry/log/provider"
+	metricProvider "go.microcore.dev/framework/telemetry/metric/provider"
+	traceProvider "go.microcore.dev/framework/telemetry/trace/provider"
+
+	"go.opentelemetry.io/otel/attribute"
+	logSdk "go.opentelemetry.io/otel/sdk/log"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type Recorder struct {
+	telemetry.Manager
+
+	t       testing.TB
+	spans   *tracetest.InMemoryExporter
+	metrics *metricSdk.ManualReader
+	logs    *logExporter
+}
+
+// New creates a telemetry.Manager that records everything in memory.
+//
+// The shutdown handler is never registered, and the global log backend is
+// switched to the in-memory log provider and restored on test cleanup.
+// Additional telemetry options (for example WithPropagator or
+// WithRuntimeMetrics) are applied after the in-memory providers are set.
+func New(t testing.TB, opts ...telemetry.Option) *Recorder {
+	t.Helper()
+
+	r := &Recorder{
+		t:       t,
+		spans:   tracetest.NewInMemoryExporter(),
+		metrics: metricSdk.NewManualReader(),
+		logs:    &logExporter{},
+	}
+
+	options := []telemetry.Option{
+		telemetry.WithTraceProviderOptions(
+			traceProvider.WithSyncer(r.spans),
+		),
+		telemetry.WithMetricProviderOptions(
+			metricProvider.WithReader(r.metrics),
+		),
+		telemetry.WithLogProviderOptions(
+			logProvider.WithProcessor(
+				logSdk.NewSimpleProcessor(r.logs),
+			),
+		),
+	}
+
+	r.Manager = telemetry.New(
+		append(
+			append(options, opts...),
+			telemetry.WithoutShutdownHandler(),
+		)...,
+	)
+
+	t.Cleanup(func() {
+		if err := r.Manager.Shutdown(context.Background(), 0); err != nil {
+			t.Errorf("telemetrytest: shutdown failed: %v", err)
+		}
+		log.SetDefaultState()
+	})
+
+	return r
+}
+
+// Spans returns all ended spans in the order they ended.
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.spans.GetSpans()
+}
+
+// FindSpan returns the first ended span with the given name that has all of
+// the given attributes.
+func (r *Recorder) FindSpan(name string, attrs ...attribute.KeyValue) (tracetest.SpanStub, bool) {
+	for _, s := range r.spans.GetSpans() {
+		if s.Name == name && hasAttributes(attribute.NewSet(s.Attributes...), attrs) {
+			return s, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// AssertSpan fails the test if no ended span with the given name and
+// attributes was recorded. It returns the matching span.
+func (r *Recorder) AssertSpan(name string, attrs ...attribute.KeyValue) tracetest.SpanStub {
+	r.t.Helper()
+
+	s, ok := r.FindSpan(name, attrs...)
+	if !ok {
+		names := []string{}
+		for _, s := range r.spans.GetSpans() {
+			names = append(names, s.Name)
+		}
+		r.t.Errorf("span %q with attributes %v not found, recorded spans: %v", name, attrs, names)
+	}
+	return s
+}
+
+// Metrics collects and returns the current state of all metrics.
+func (r *Recorder) Metrics() metricdata.ResourceMetrics {
+	r.t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.metrics.Collect(context.Background(), &rm); err != nil {
+		r.t.Fatalf("telemetrytest: failed to collect metrics: %v", err)
+	}
+	return rm
+}
+
+// MetricValue collects metrics and returns the value of the first data point
+// of the named metric that has all of the given attributes.
+//
+// Sums and gauges return their value; histograms return the sum of all
+// recorded measurements. The second result is false if no such data point
+// exists.
+func (r *Recorder) MetricValue(name string, attrs ...attribute.KeyValue) (float64, bool) {
+	r.t.Helper()
+
+	rm := r.Metrics()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			if v, ok := dataPointValue(m.Data, attrs); ok {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Logs returns all emitted log records in the order they were emitted.
+func (r *Recorder) Logs() []logSdk.Record {
+	return r.logs.get()
+}
+
+// Reset discards all recorded spans and log records. Metrics are cumulative
+// and are not affected.
+func (r *Recorder) Reset() {
+	r.spans.Reset()
+	r.logs.reset()
+}
+
+func dataPointValue(data metricdata.Aggregation, attrs []attribute.KeyValue) (float64, bool) {
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return float64(dp.Value), true
+			}
+		}
+	case metricdata.Sum[float64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return dp.Value, true
+			}
+		}
+	case metricdata.Gauge[int64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return float64(dp.Value), true
+			}
+		}
+	case metricdata.Gauge[float64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return dp.Value, true
+			}
+		}
+	case metricdata.Histogram[int64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return float64(dp.Sum), true
+			}
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range d.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				return dp.Sum, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func hasAttributes(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, a := range attrs {
+		v, ok := set.Value(a.Key)
+		if !ok || v != a.Value {
+			return false
+		}
+	}
+	return true
+}
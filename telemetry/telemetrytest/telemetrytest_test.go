@@ -0,0 +1,97 @@
+package telemetrytest_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/telemetry"
+	"go.microcore.dev/framework/telemetry/telemetrytest"
+	"go.microcore.dev/framework/transport/http/client"
+	"go.microcore.dev/framework/transport/http/server"
+)
+
+func TestRecorder_HttpRoundTrip(t *testing.T) {
+	tm := telemetrytest.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := server.New(
+		server.WithListener(ln),
+		server.WithTelemetryManager(tm),
+		server.WithoutShutdownHandler(),
+	)
+	require.NoError(t, err)
+	srv.AddRoute(server.WithRoutePath("/ping"))
+
+	exit := srv.Listen()
+	t.Cleanup(func() {
+		require.NoError(t, srv.Shutdown(context.Background(), 0))
+		<-exit
+	})
+
+	resp, err := client.New(client.WithTelemetryManager(tm)).Request("http://" + ln.Addr().String() + "/ping")
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode())
+
+	in := tm.AssertSpan("incoming http request", attribute.Int("status", 200))
+	out := tm.AssertSpan("outgoing http request", attribute.Int("response.status_code", 200))
+	require.Equal(t, out.SpanContext.TraceID(), in.SpanContext.TraceID())
+	require.Equal(t, out.SpanContext.SpanID(), in.Parent.SpanID())
+}
+
+func TestRecorder_MetricValue(t *testing.T) {
+	tm := telemetrytest.New(t)
+
+	counter, err := tm.GetMeter().Int64Counter("requests")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	counter.Add(ctx, 2, metricAttrs("GET"))
+	counter.Add(ctx, 3, metricAttrs("GET"))
+	counter.Add(ctx, 7, metricAttrs("POST"))
+
+	v, ok := tm.MetricValue("requests", attribute.String("method", "GET"))
+	require.True(t, ok)
+	require.Equal(t, float64(5), v)
+
+	_, ok = tm.MetricValue("requests", attribute.String("method", "PUT"))
+	require.False(t, ok)
+}
+
+func TestRecorder_Logs(t *testing.T) {
+	tm := telemetrytest.New(t)
+
+	log.New("test").Info("hello", "key", "value")
+
+	logs := tm.Logs()
+	require.Len(t, logs, 1)
+	require.Equal(t, "hello", logs[0].Body().AsString())
+
+	tm.Reset()
+	require.Empty(t, tm.Logs())
+}
+
+func TestRecorder_LogsOtherManagerShutdown(t *testing.T) {
+	tm := telemetrytest.New(t)
+
+	// Another manager adds its own sink, and removes only that one
+	other := telemetry.New(telemetry.WithoutShutdownHandler())
+	require.NoError(t, other.Shutdown(context.Background(), 0))
+	tm.Reset()
+
+	log.New("test").Info("hello")
+	logs := tm.Logs()
+	require.Len(t, logs, 1)
+	require.Equal(t, "hello", logs[0].Body().AsString())
+}
+
+func metricAttrs(method string) metric.AddOption {
+	return metric.WithAttributes(attribute.String("method", method))
+}
@@ -12,6 +12,25 @@ type (
 		Base error
 		Err  error
 		Code string
+
+		// Held by pointer, so that Error stays comparable and can be used
+		// as a map key or compared with ==.
+		details *[]FieldError
+	}
+
+	// FieldError describes a field of a request or message that failed
+	// decoding or validation.
+	FieldError struct {
+		// Field is the path of the field, e.g. "items[0].name".
+		Field string `json:"field"`
+
+		// In is where the field was read from, e.g. "query" or "body".
+		In string `json:"in,omitempty"`
+
+		// Rule is the validation rule that failed, e.g. "required".
+		Rule string `json:"rule,omitempty"`
+
+		Message string `json:"message"`
 	}
 )
 
@@ -23,6 +42,16 @@ func NewError(base error, message string, code string) error {
 	}
 }
 
+// NewErrorWithDetails returns an Error listing the offending fields.
+func NewErrorWithDetails(base error, message string, code string, details []FieldError) error {
+	return Error{
+		Base:    base,
+		Err:     errors.New(message),
+		Code:    code,
+		details: &details,
+	}
+}
+
 func (e Error) Error() string {
 	return fmt.Sprintf("%v: %v", e.Base, e.Err)
 }
@@ -35,6 +64,21 @@ func (e Error) GetCode() string {
 	return e.Code
 }
 
+func (e Error) GetBase() error {
+	return e.Base
+}
+
+func (e Error) GetDetails() []FieldError {
+	if e.details == nil {
+		return nil
+	}
+	return *e.details
+}
+
+func (f FieldError) Error() string {
+	return f.Field + ": " + f.Message
+}
+
 var (
 	// Client errors — issues caused by the client's request
 
@@ -67,6 +111,11 @@ var (
 	// This occurs when, for example, POST is used on a read-only endpoint.
 	ErrMethodNotAllowed error = errors.New("method not allowed")
 
+	// None of the representations of the resource matches the Accept
+	// headers of the request, for example when only JSON can be produced
+	// and the client accepts XML only.
+	ErrNotAcceptable error = errors.New("not acceptable")
+
 	// There is a conflict with the current state of the resource,
 	// such as attempting to create a resource that already exists
 	// or modify one in an inconsistent state.
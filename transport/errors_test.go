@@ -0,0 +1,26 @@
+package transport
+
+import (
+	"testing"
+)
+
+func TestErrorComparable(t *testing.T) {
+	err := NewErrorWithDetails(ErrBadRequest, "invalid request", "INVALID", []FieldError{{Field: "name", Rule: "required"}})
+	// Comparing errors holding non-comparable fields panics
+	same := err
+	if err != same {
+		t.Error("error not equal to its copy")
+	}
+
+	statusCodes := map[error]int{err: 400}
+	if statusCodes[NewError(err, "wrapped", "WRAPPED")] != 0 {
+		t.Error("wrapped error found in map")
+	}
+
+	if e := err.(Error); len(e.GetDetails()) != 1 || e.GetDetails()[0].Field != "name" {
+		t.Errorf("details: got %+v", e.GetDetails())
+	}
+	if details := NewError(ErrBadRequest, "invalid request", "INVALID").(Error).GetDetails(); details != nil {
+		t.Errorf("no details: got %+v", details)
+	}
+}
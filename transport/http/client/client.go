@@ -12,6 +12,7 @@ import (
 	"go.microcore.dev/framework/log"
 	"go.microcore.dev/framework/telemetry"
 	"go.microcore.dev/framework/transport/http/client/core"
+	"go.microcore.dev/framework/transport/http/codec"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -27,6 +28,7 @@ type Manager interface {
 type client struct {
 	core      *fasthttp.Client
 	telemetry telemetry.Manager
+	codecs    *codec.Registry
 }
 
 type request struct {
@@ -34,6 +36,12 @@ type request struct {
 	method  string
 	body    []byte
 	headers []requestHeader
+	encode  *requestEncode
+}
+
+type requestEncode struct {
+	contentType string
+	data        any
 }
 
 type requestHeader struct {
@@ -44,7 +52,9 @@ type requestHeader struct {
 var logger = log.New(pkg)
 
 func New(opts ...Option) Manager {
-	client := &client{}
+	client := &client{
+		codecs: codec.NewRegistry(),
+	}
 
 	for _, opt := range opts {
 		opt(client)
@@ -93,6 +103,22 @@ func (c *client) Request(url string, opts ...RequestOption) (*response, error) {
 		}
 	}
 
+	if request.encode != nil {
+		cd, ok := c.codecs.Get(request.encode.contentType)
+		if !ok {
+			return nil, fmt.Errorf("unsupported content type %q", request.encode.contentType)
+		}
+		body, err := cd.Marshal(request.encode.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		request.body = body
+		request.headers = append(
+			[]requestHeader{NewRequestHeader("Content-Type", cd.ContentType())},
+			request.headers...,
+		)
+	}
+
 	resCh := make(chan *response, 1)
 	errCh := make(chan error, 1)
 	go func() {
@@ -138,7 +164,7 @@ func (c *client) Request(url string, opts ...RequestOption) (*response, error) {
 		}
 		cResp := &fasthttp.Response{}
 		resp.CopyTo(cResp)
-		resCh <- &response{cResp}
+		resCh <- &response{cResp, c.codecs}
 	}()
 	select {
 	case <-request.context.Done():
@@ -2,14 +2,13 @@ package client // import "go.microcore.dev/framework/transport/http/client"
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/valyala/fasthttp"
 
 	_ "go.microcore.dev/framework"
 	"go.microcore.dev/framework/telemetry"
 	"go.microcore.dev/framework/transport/http/client/core"
+	"go.microcore.dev/framework/transport/http/codec"
 )
 
 type Option func(*client)
@@ -32,6 +31,15 @@ func WithTelemetryManager(telemetry telemetry.Manager) Option {
 	}
 }
 
+// WithCodecs registers codecs used by WithRequestEncodedBody and
+// response.Decode, replacing the built-in codecs of the same media types.
+// See codec.Registry.
+func WithCodecs(codecs ...codec.Codec) Option {
+	return func(s *client) {
+		s.codecs.Register(codecs...)
+	}
+}
+
 type RequestOption func(*request) error
 
 func WithRequestContext(context context.Context) RequestOption {
@@ -51,22 +59,29 @@ func WithRequestMethod(method string) RequestOption {
 func WithRequestBody(body []byte) RequestOption {
 	return func(r *request) error {
 		r.body = body
+		r.encode = nil
 		return nil
 	}
 }
 
-// WithRequestJsonBody serializes the given data to JSON and returns a RequestOption
-// that sets it as the request body.
-//
-// If serialization fails, the function logs the error and immediately terminates
-// the program with exit code 65 (ExitDataError), indicating invalid input data.
+// WithRequestJsonBody sets the request body to data encoded with the JSON
+// codec of the client and sets the Content-Type header. Encoding errors are
+// returned by Request. See WithRequestEncodedBody.
 func WithRequestJsonBody(data any) RequestOption {
+	return WithRequestEncodedBody(codec.ContentTypeJson, data)
+}
+
+// WithRequestEncodedBody sets the request body to data encoded with the
+// codec of contentType, e.g. codec.ContentTypeMsgPack, and sets the
+// Content-Type header. The codec of the preferred representation (JSON by
+// default) is used if contentType is empty.
+func WithRequestEncodedBody(contentType string, data any) RequestOption {
 	return func(r *request) error {
-		bytes, err := json.Marshal(data)
-		if err != nil {
-			return fmt.Errorf("failed to parse json body: %w", err)
+		r.body = nil
+		r.encode = &requestEncode{
+			contentType: contentType,
+			data:        data,
 		}
-		r.body = bytes
 		return nil
 	}
 }
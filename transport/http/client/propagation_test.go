@@ -0,0 +1,30 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"go.microcore.dev/framework/telemetry/telemetrytest"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestPropagation(t *testing.T) {
+	telemetrytest.AssertPropagation(t, func(inject func(propagation.TextMapCarrier)) propagation.TextMapCarrier {
+		var out fasthttp.Request
+		out.SetRequestURI("http://example.com/x")
+		inject(fasthttpRequestHeaderCarrier{&out.Header})
+
+		var wire bytes.Buffer
+		if _, err := out.WriteTo(&wire); err != nil {
+			t.Fatal(err)
+		}
+		var in fasthttp.Request
+		if err := in.Read(bufio.NewReader(&wire)); err != nil {
+			t.Fatal(err)
+		}
+		return fasthttpRequestHeaderCarrier{&in.Header}
+	})
+}
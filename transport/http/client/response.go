@@ -1,11 +1,25 @@
 package client // import "go.microcore.dev/framework/transport/http/client"
 
 import (
+	"fmt"
+
 	"github.com/valyala/fasthttp"
 
 	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport/http/codec"
 )
 
 type response struct {
 	*fasthttp.Response
+	codecs *codec.Registry
+}
+
+// Decode decodes the response body into v with the codec of its
+// Content-Type header, or of the preferred representation if absent.
+func (r *response) Decode(v any) error {
+	cd, ok := r.codecs.Get(string(r.Header.ContentType()))
+	if !ok {
+		return fmt.Errorf("unsupported content type %q", r.Header.ContentType())
+	}
+	return cd.Unmarshal(r.Body(), v)
 }
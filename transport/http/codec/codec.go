@@ -0,0 +1,188 @@
+package codec // import "go.microcore.dev/framework/transport/http/codec"
+
+/*
+Package codec encodes and decodes HTTP bodies in the representations
+negotiated from the Content-Type and Accept headers.
+
+A Registry holds the supported codecs in order of preference. NewRegistry
+registers the built-in JSON, XML, MessagePack and protobuf codecs, and
+Register adds codecs or replaces the ones of the same media type, e.g. to
+use a faster JSON implementation:
+
+	registry := codec.NewRegistry()
+	registry.Register(codec.NewJson(sonic.Marshal, sonic.Unmarshal))
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// Codec encodes and decodes values in a representation.
+	Codec interface {
+		// Name returns the short name of the representation, e.g. json.
+		Name() string
+		// ContentType returns the Content-Type header of encoded values,
+		// e.g. application/json; charset=utf-8.
+		ContentType() string
+		Marshal(v any) ([]byte, error)
+		Unmarshal(data []byte, v any) error
+	}
+
+	// Matcher is implemented by codecs encoding some types only, such as
+	// the protobuf codec, which encodes proto.Message values.
+	Matcher interface {
+		Match(v any) bool
+	}
+
+	jsonCodec struct {
+		marshal   func(v any) ([]byte, error)
+		unmarshal func(data []byte, v any) error
+	}
+
+	xmlCodec struct{}
+
+	msgPackCodec struct {
+		tag string
+	}
+
+	protobufCodec struct{}
+)
+
+// NewJson returns a JSON codec using marshal and unmarshal, which have the
+// signatures of json.Marshal and json.Unmarshal. Nil functions default to
+// encoding/json.
+func NewJson(marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) Codec {
+	if marshal == nil {
+		marshal = json.Marshal
+	}
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	return &jsonCodec{
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+}
+
+func (c *jsonCodec) Name() string {
+	return "json"
+}
+
+func (c *jsonCodec) ContentType() string {
+	return ContentTypeJson + "; charset=utf-8"
+}
+
+func (c *jsonCodec) Marshal(v any) ([]byte, error) {
+	return c.marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v any) error {
+	return c.unmarshal(data, v)
+}
+
+// NewXml returns an XML codec using encoding/xml.
+func NewXml() Codec {
+	return xmlCodec{}
+}
+
+func (xmlCodec) Name() string {
+	return "xml"
+}
+
+func (xmlCodec) ContentType() string {
+	return ContentTypeXml + "; charset=utf-8"
+}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// NewMsgPack returns a MessagePack codec naming struct fields after their
+// DefaultMsgPackTag tag.
+func NewMsgPack() Codec {
+	return msgPackCodec{tag: DefaultMsgPackTag}
+}
+
+func (msgPackCodec) Name() string {
+	return "msgpack"
+}
+
+func (msgPackCodec) ContentType() string {
+	return ContentTypeMsgPack
+}
+
+func (c msgPackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag(c.tag)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c msgPackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag(c.tag)
+	return dec.Decode(v)
+}
+
+// NewProtobuf returns a protobuf codec, encoding proto.Message values only.
+func NewProtobuf() Codec {
+	return protobufCodec{}
+}
+
+func (protobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (protobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (protobufCodec) Match(v any) bool {
+	_, ok := v.(proto.Message)
+	return ok
+}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// MediaType returns the lower case media type of a Content-Type header
+// without parameters, e.g. application/json.
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	return mediaType
+}
@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type item struct {
+	Name  string `json:"name" xml:"name"`
+	Count int    `json:"count,omitempty" xml:"count"`
+}
+
+func TestCodecs(t *testing.T) {
+	r := NewRegistry()
+
+	for _, contentType := range []string{ContentTypeJson, ContentTypeXml, ContentTypeMsgPack} {
+		c, ok := r.Get(contentType)
+		if !ok {
+			t.Fatalf("codec %s not registered", contentType)
+		}
+		data, err := c.Marshal(item{Name: "a", Count: 2})
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", c.Name(), err)
+		}
+		var got item
+		if err := c.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: unmarshal: %v", c.Name(), err)
+		}
+		if got != (item{Name: "a", Count: 2}) {
+			t.Errorf("%s: got %+v", c.Name(), got)
+		}
+	}
+
+	// MessagePack fields are named after json tags
+	c, _ := r.Get(ContentTypeMsgPack)
+	data, _ := c.Marshal(map[string]any{"name": "b"})
+	var got item
+	if err := c.Unmarshal(data, &got); err != nil || got.Name != "b" {
+		t.Errorf("msgpack: got %+v, %v", got, err)
+	}
+
+	c, _ = r.Get(ContentTypeProtobuf)
+	data, err := c.Marshal(wrapperspb.String("c"))
+	if err != nil {
+		t.Fatalf("protobuf: marshal: %v", err)
+	}
+	msg := &wrapperspb.StringValue{}
+	if err := c.Unmarshal(data, msg); err != nil || msg.Value != "c" {
+		t.Errorf("protobuf: got %v, %v", msg, err)
+	}
+	if _, err := c.Marshal(item{}); err == nil {
+		t.Error("protobuf: expected error for non proto.Message")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	if c, ok := r.Get(""); !ok || c.Name() != "json" {
+		t.Errorf("empty content type: got %v", c)
+	}
+	if c, ok := r.Get("Application/XML; charset=utf-8"); !ok || c.Name() != "xml" {
+		t.Errorf("xml content type: got %v", c)
+	}
+	if c, ok := r.Get("application/vnd.api+json"); !ok || c.Name() != "json" {
+		t.Errorf("+json content type: got %v", c)
+	}
+	if _, ok := r.Get("text/csv"); ok {
+		t.Error("text/csv: expected no codec")
+	}
+	if _, ok := r.Get("application/x-www-form-urlencoded"); ok {
+		t.Error("form content type: expected no codec")
+	}
+
+	// Replacing a codec keeps its preference
+	called := false
+	r.Register(NewJson(func(v any) ([]byte, error) {
+		called = true
+		return json.Marshal(v)
+	}, nil))
+	c, _ := r.Get(ContentTypeJson)
+	c.Marshal(1)
+	if !called || len(r.Codecs()) != 4 || r.Codecs()[0] != c {
+		t.Error("json codec not replaced")
+	}
+
+	if got, want := r.ContentTypes(item{}), []string{ContentTypeJson, ContentTypeXml, ContentTypeMsgPack}; !reflect.DeepEqual(got, want) {
+		t.Errorf("content types: got %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		accept string
+		v      any
+		want   string
+	}{
+		{"", item{}, "json"},
+		{"*/*", item{}, "json"},
+		{"application/xml", item{}, "xml"},
+		{"application/json;q=0.5, application/*", item{}, "xml"},
+		{"application/json;q=0, */*", item{}, "xml"},
+		{"application/msgpack;q=0.9, application/json;q=0.8", item{}, "msgpack"},
+		{"application/vnd.api+json", item{}, "json"},
+		{"application/vnd.api+json, application/xml;q=0.5", item{}, "json"},
+		{"application/vnd.api+json;q=0.5, application/xml", item{}, "xml"},
+		{"application/json;q=0, application/vnd.api+json", item{}, ""},
+		{"application/vnd.api+yaml", item{}, ""},
+		{"application/x-protobuf", item{}, ""},
+		{"application/x-protobuf", wrapperspb.String(""), "protobuf"},
+		{"text/html", item{}, ""},
+	}
+	for _, tt := range tests {
+		c, ok := r.Negotiate(tt.accept, tt.v)
+		got := ""
+		if ok {
+			got = c.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Negotiate(%q, %T): got %q, want %q", tt.accept, tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package codec // import "go.microcore.dev/framework/transport/http/codec"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/transport/http/codec"
+
+	ContentTypeJson     = "application/json"
+	ContentTypeXml      = "application/xml"
+	ContentTypeMsgPack  = "application/msgpack"
+	ContentTypeProtobuf = "application/x-protobuf"
+
+	// Struct tag naming fields of MessagePack codecs, shared with JSON so
+	// fields have the same names in both representations.
+	DefaultMsgPackTag = "json"
+)
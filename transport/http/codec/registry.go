@@ -0,0 +1,170 @@
+package codec // import "go.microcore.dev/framework/transport/http/codec"
+
+import (
+	"strconv"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+)
+
+// Registry holds codecs in order of preference. It is not safe for
+// concurrent use while codecs are registered.
+type Registry struct {
+	codecs []Codec
+}
+
+// NewRegistry returns a registry of the JSON, XML, MessagePack and protobuf
+// codecs, in this order, and of codecs.
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{}
+	r.Register(
+		NewJson(nil, nil),
+		NewXml(),
+		NewMsgPack(),
+		NewProtobuf(),
+	)
+	r.Register(codecs...)
+	return r
+}
+
+// Register adds codecs after the registered ones. A codec replaces the
+// registered codec of the same media type, keeping its preference.
+func (r *Registry) Register(codecs ...Codec) {
+	for _, codec := range codecs {
+		mediaType := MediaType(codec.ContentType())
+		replaced := false
+		for i, c := range r.codecs {
+			if MediaType(c.ContentType()) == mediaType {
+				r.codecs[i], replaced = codec, true
+				break
+			}
+		}
+		if !replaced {
+			r.codecs = append(r.codecs, codec)
+		}
+	}
+}
+
+// Codecs returns the registered codecs in order of preference.
+func (r *Registry) Codecs() []Codec {
+	return r.codecs
+}
+
+// Get returns the codec of the media type of contentType, or the preferred
+// codec if contentType is empty. Media types with a structured syntax
+// suffix, e.g. application/vnd.api+json, fall back to the codec of the
+// suffix, e.g. application/json.
+func (r *Registry) Get(contentType string) (Codec, bool) {
+	if strings.TrimSpace(contentType) == "" {
+		if len(r.codecs) == 0 {
+			return nil, false
+		}
+		return r.codecs[0], true
+	}
+
+	mediaType := MediaType(contentType)
+	for _, c := range r.codecs {
+		if MediaType(c.ContentType()) == mediaType {
+			return c, true
+		}
+	}
+
+	if suffix, ok := suffixType(mediaType); ok {
+		for _, c := range r.codecs {
+			if MediaType(c.ContentType()) == suffix {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ContentTypes returns the media types of the codecs able to encode v, see
+// Matcher.
+func (r *Registry) ContentTypes(v any) []string {
+	contentTypes := []string{}
+	for _, c := range r.codecs {
+		if match(c, v) {
+			contentTypes = append(contentTypes, MediaType(c.ContentType()))
+		}
+	}
+	return contentTypes
+}
+
+// Negotiate returns the codec able to encode v with the highest quality in
+// the Accept header, preferring earlier codecs on ties. The quality of a
+// codec is the one of the most specific matching media range. Media ranges
+// with a structured syntax suffix match the codec of the suffix, like in
+// Get, but less specifically than the media type of the codec. An empty
+// Accept header accepts the preferred codec.
+func (r *Registry) Negotiate(accept string, v any) (Codec, bool) {
+	var (
+		best  Codec
+		bestQ float64
+	)
+	for _, c := range r.codecs {
+		if !match(c, v) {
+			continue
+		}
+		if strings.TrimSpace(accept) == "" {
+			return c, true
+		}
+
+		offer := MediaType(c.ContentType())
+		offerType, _, _ := strings.Cut(offer, "/")
+
+		q, specificity := 0.0, -1
+		for part := range strings.SplitSeq(accept, ",") {
+			mediaType, params, _ := strings.Cut(part, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+			var s int
+			switch suffix, _ := suffixType(mediaType); {
+			case mediaType == offer:
+				s = 3
+			case suffix == offer:
+				s = 2
+			case mediaType == offerType+"/*":
+				s = 1
+			case mediaType == "*/*":
+				s = 0
+			default:
+				continue
+			}
+			if s <= specificity {
+				continue
+			}
+
+			specificity, q = s, 1.0
+			for param := range strings.SplitSeq(params, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if k == "q" {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						q = f
+					}
+				}
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+
+	return best, best != nil
+}
+
+// suffixType returns the media type of the structured syntax suffix of
+// mediaType, e.g. application/json for application/vnd.api+json.
+func suffixType(mediaType string) (string, bool) {
+	i := strings.LastIndexByte(mediaType, '+')
+	if i < 0 {
+		return "", false
+	}
+	return "application/" + mediaType[i+1:], true
+}
+
+func match(c Codec, v any) bool {
+	m, ok := c.(Matcher)
+	return !ok || v == nil || m.Match(v)
+}
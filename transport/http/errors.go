@@ -13,6 +13,7 @@ var ErrStatusCodeMap = map[error]StatusCode{
 	transport.ErrForbidden:                    StatusForbidden,
 	transport.ErrNotFound:                     StatusNotFound,
 	transport.ErrMethodNotAllowed:             StatusMethodNotAllowed,
+	transport.ErrNotAcceptable:                StatusNotAcceptable,
 	transport.ErrConflict:                     StatusConflict,
 	transport.ErrGone:                         StatusGone,
 	transport.ErrRequestEntityTooLarge:        StatusPayloadTooLarge,
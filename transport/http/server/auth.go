@@ -0,0 +1,120 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"errors"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/auth"
+	"go.microcore.dev/framework/transport"
+)
+
+type authentication struct {
+	optional bool
+}
+
+// AuthMiddleware returns a middleware verifying the bearer token of requests
+// with verifier and adding its claims to the request context, see
+// RequestContext.GetClaims and auth.FromContext. It may be added to routes,
+// route groups or the whole server with AddMiddleware.
+//
+// Requests without a valid token are answered with an error wrapping
+// transport.ErrUnauthorized and a WWW-Authenticate header, unless
+// WithAuthOptional is set and the token is missing. Routes may then require
+// roles and scopes with WithRouteRoles and WithRouteScopes.
+//
+// Example:
+//
+//	verifier := auth.NewVerifier(
+//	    auth.WithOIDCIssuer("https://id.example.com/realms/main"),
+//	    auth.WithAudience("orders"),
+//	)
+//
+//	manager.AddRouteGroup(
+//	    server.WithRouteGroupPath("/admin"),
+//	    server.WithRouteGroupMiddlewares(server.AuthMiddleware(verifier)),
+//	    server.WithRouteGroupRoute(
+//	        server.WithRouteRoles("admin"),
+//	        server.WithRouteHandler(handler),
+//	    ),
+//	)
+func AuthMiddleware(verifier *auth.Verifier, opts ...AuthOption) MiddlewareHandler {
+	a := &authentication{}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return func(next RequestHandler) RequestHandler {
+		return func(c *RequestContext) {
+			token, err := c.GetBearerToken()
+			if err != nil || token == "" {
+				if a.optional {
+					next(c)
+					return
+				}
+				c.WriteError(transport.NewError(transport.ErrUnauthorized, "missing bearer token", "MISSING_TOKEN"))
+				c.Response.Header.Set("WWW-Authenticate", "Bearer")
+				return
+			}
+
+			ctx := c.GetContext()
+			claims, err := verifier.Verify(ctx, token)
+			if err != nil {
+				c.WriteError(err)
+				if errors.Is(err, transport.ErrUnauthorized) {
+					c.Response.Header.Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+				return
+			}
+
+			c.SetUserValue("ctx", auth.NewContext(ctx, claims))
+			next(c)
+		}
+	}
+}
+
+// GetClaims returns the claims of the token verified by AuthMiddleware.
+func (c *RequestContext) GetClaims() (*auth.Claims, bool) {
+	return auth.FromContext(c.GetContext())
+}
+
+// authorize wraps the handler of route, checking the roles and scopes of
+// WithRouteRoles and WithRouteScopes.
+func authorize(route *route, handler RequestHandler) RequestHandler {
+	if len(route.roles) == 0 && len(route.scopes) == 0 {
+		return handler
+	}
+
+	return func(c *RequestContext) {
+		claims, ok := c.GetClaims()
+		if !ok {
+			c.WriteError(transport.NewError(transport.ErrUnauthorized, "missing bearer token", "MISSING_TOKEN"))
+			c.Response.Header.Set("WWW-Authenticate", "Bearer")
+			return
+		}
+		if len(route.roles) > 0 && !claims.HasRole(route.roles...) {
+			c.WriteError(transport.NewError(transport.ErrForbidden, "insufficient role", "INSUFFICIENT_ROLE"))
+			return
+		}
+		if !claims.HasScopes(route.scopes...) {
+			c.WriteError(transport.NewError(transport.ErrForbidden, "insufficient scope", "INSUFFICIENT_SCOPE"))
+			c.Response.Header.Set(
+				"WWW-Authenticate",
+				`Bearer error="insufficient_scope", scope="`+strings.Join(route.scopes, " ")+`"`,
+			)
+			return
+		}
+		handler(c)
+	}
+}
+
+// routeAuthErrors returns the errors of routes requiring roles or scopes,
+// documented in the OpenAPI document.
+func routeAuthErrors(forbiddenCode string) []error {
+	return []error{
+		transport.NewError(transport.ErrUnauthorized, "missing bearer token", "MISSING_TOKEN"),
+		transport.NewError(transport.ErrUnauthorized, "invalid token", "INVALID_TOKEN"),
+		transport.NewError(transport.ErrForbidden, "forbidden", forbiddenCode),
+	}
+}
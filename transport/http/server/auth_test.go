@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.microcore.dev/framework/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func bearer(t *testing.T, secret []byte, roles []string, scope string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"roles": roles,
+		"scope": scope,
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	verifier := auth.NewVerifier(auth.WithHmacSecret(secret))
+	handler := WithRouteHandler(func(_ context.Context, c *RequestContext) {
+		subject := "anonymous"
+		if claims, ok := c.GetClaims(); ok {
+			subject = claims.Subject
+		}
+		c.WriteString(subject)
+	})
+
+	h := routeHandler(
+		WithRoutePath("/orders"),
+		WithRouteMiddlewares(AuthMiddleware(verifier)),
+		WithRouteRoles("admin", "clerk"),
+		WithRouteScopes("orders:read", "orders:write"),
+		handler,
+	)
+
+	for _, tt := range []struct {
+		name          string
+		authorization string
+		status        int
+		code          string
+		authenticate  string
+	}{
+		{"missing token", "", 401, "MISSING_TOKEN", "Bearer"},
+		{"invalid token", "Bearer invalid", 401, "INVALID_TOKEN", `Bearer error="invalid_token"`},
+		{"wrong secret", bearer(t, []byte("other"), []string{"admin"}, "orders:read orders:write"), 401, "INVALID_TOKEN", `Bearer error="invalid_token"`},
+		{"insufficient role", bearer(t, secret, []string{"viewer"}, "orders:read orders:write"), 403, "INSUFFICIENT_ROLE", ""},
+		{"insufficient scope", bearer(t, secret, []string{"clerk"}, "orders:read"), 403, "INSUFFICIENT_SCOPE", `Bearer error="insufficient_scope", scope="orders:read orders:write"`},
+	} {
+		headers := []string{}
+		if tt.authorization != "" {
+			headers = append(headers, "Authorization", tt.authorization)
+		}
+		resp := request(h, "GET", "/orders", nil, headers...)
+		if resp.StatusCode() != tt.status || errResponse(t, resp).Code != tt.code {
+			t.Errorf("%s: got %d %s", tt.name, resp.StatusCode(), resp.Body())
+		}
+		if got := string(resp.Header.Peek("WWW-Authenticate")); got != tt.authenticate {
+			t.Errorf("%s: got WWW-Authenticate %q, want %q", tt.name, got, tt.authenticate)
+		}
+	}
+
+	resp := request(h, "GET", "/orders", nil, "Authorization", bearer(t, secret, []string{"clerk"}, "orders:read orders:write"))
+	if resp.StatusCode() != 200 || string(resp.Body()) != "user-1" {
+		t.Errorf("authorized: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	// Optional authentication lets requests without token through, unless
+	// the route requires roles
+	optional := AuthMiddleware(verifier, WithAuthOptional())
+	h = routeHandler(WithRoutePath("/orders"), WithRouteMiddlewares(optional), handler)
+	if resp := request(h, "GET", "/orders", nil); resp.StatusCode() != 200 || string(resp.Body()) != "anonymous" {
+		t.Errorf("optional: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	if resp := request(h, "GET", "/orders", nil, "Authorization", "Bearer invalid"); resp.StatusCode() != 401 {
+		t.Errorf("optional with invalid token: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	h = routeHandler(WithRoutePath("/orders"), WithRouteMiddlewares(optional), WithRouteRoles("admin"), handler)
+	if resp := request(h, "GET", "/orders", nil); resp.StatusCode() != 401 || errResponse(t, resp).Code != "MISSING_TOKEN" {
+		t.Errorf("optional with roles: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	// Key sets that cannot be loaded are not token errors
+	verifier = auth.NewVerifier(auth.WithJwksFile(filepath.Join(t.TempDir(), "missing.json")))
+	h = routeHandler(WithRoutePath("/orders"), WithRouteMiddlewares(AuthMiddleware(verifier)), handler)
+	resp = request(h, "GET", "/orders", nil, "Authorization", bearer(t, secret, nil, ""))
+	if resp.StatusCode() < 500 || len(resp.Header.Peek("WWW-Authenticate")) != 0 {
+		t.Errorf("missing jwks: got %d %s", resp.StatusCode(), resp.Body())
+	}
+}
@@ -0,0 +1,381 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/validate"
+)
+
+const (
+	BindInPath   = "path"
+	BindInQuery  = "query"
+	BindInHeader = "header"
+	BindInBody   = "body"
+
+	// Rule of binding errors, see transport.FieldError. Body decoding
+	// errors have the codec name as rule.
+	bindRuleType = "type"
+)
+
+type (
+	bindField struct {
+		index []int
+		name  string
+		in    string
+		def   string
+	}
+
+	bindInfo struct {
+		fields []bindField
+		body   bool
+	}
+)
+
+var (
+	bindInfoCache sync.Map
+
+	// Field names of validation errors
+	bindNameTags = []string{BindInPath, BindInQuery, BindInHeader, "json"}
+
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// WithRouteBindHandler binds the request to Req with RequestContext.Bind
+// before calling handler. Binding and validation errors are written as a
+// 400 ErrResponse listing every offending field.
+//
+// Example:
+//
+//	type ListUsers struct {
+//	    Tenant string `header:"X-Tenant" validate:"required"`
+//	    Group  int    `path:"group"`
+//	    Limit  int    `query:"limit" default:"20"`
+//	}
+//
+//	server.WithRouteBindHandler(func(ctx context.Context, c *server.RequestContext, req *ListUsers) {
+//	    // ...
+//	})
+func WithRouteBindHandler[Req any](handler func(context.Context, *RequestContext, *Req)) RouteOption {
+	return func(r *route) {
+		r.handler = func(c *RequestContext) {
+			var req Req
+			if err := c.Bind(&req); err != nil {
+				c.WriteError(err)
+				return
+			}
+			handler(extractRequestContext(c.RequestCtx), c, &req)
+		}
+		r.doc.bind = reflect.TypeFor[Req]()
+		r.doc.errors = append(r.doc.errors, bindErrors(r.doc.bind)...)
+	}
+}
+
+// Bind fills the struct pointed to by v from the request:
+//
+//   - fields tagged `path:"name"` from route parameters,
+//   - fields tagged `query:"name"` from query arguments,
+//   - fields tagged `header:"Name"` from request headers,
+//   - all other fields from the body, decoded with the codec of its
+//     Content-Type (see RequestContext.ReadBody) and named after their
+//     json tags.
+//
+// Fields are first set to their `default:"value"` tag. Parameters may be
+// strings, booleans, numbers, durations, types implementing
+// encoding.TextUnmarshaler, pointers to them, or slices of them filled from
+// repeated or comma-separated values.
+//
+// The bound struct is then checked with validate.Struct, naming fields
+// after their parameter or json names. Every field that failed binding or
+// validation is reported in the details of a transport.Error with
+// transport.ErrBadRequest as base. If v implements Validate() error, it is
+// called after successful validation.
+func (c *RequestContext) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected pointer to struct, got %T", v)
+	}
+	rv = rv.Elem()
+	info := getBindInfo(rv.Type())
+
+	details := []transport.FieldError{}
+	fail := func(field, in, rule, message string) {
+		details = append(details, transport.FieldError{
+			Field:   field,
+			In:      in,
+			Rule:    rule,
+			Message: message,
+		})
+	}
+
+	for _, f := range info.fields {
+		if f.def == "" {
+			continue
+		}
+		if err := setBindValue(rv.FieldByIndex(f.index), []string{f.def}); err != nil {
+			return fmt.Errorf("bind: invalid default for %s: %w", f.name, err)
+		}
+	}
+
+	if info.body && len(c.Request.Body()) > 0 {
+		cd, err := c.requestCodec()
+		if err != nil {
+			return err
+		}
+
+		// Decode into a copy, so the body cannot set parameter fields
+		body := reflect.New(rv.Type())
+		body.Elem().Set(rv)
+		if err := cd.Unmarshal(c.Request.Body(), body.Interface()); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) && typeErr.Field != "" {
+				fail(typeErr.Field, BindInBody, bindRuleType, "must be "+jsonTypeName(typeErr.Type))
+			} else {
+				fail(BindInBody, BindInBody, cd.Name(), "invalid "+cd.Name())
+			}
+		}
+		for _, f := range info.fields {
+			if f.in == BindInBody {
+				rv.FieldByIndex(f.index).Set(body.Elem().FieldByIndex(f.index))
+			}
+		}
+	}
+
+	for _, f := range info.fields {
+		field := rv.FieldByIndex(f.index)
+
+		var values []string
+		switch f.in {
+		case BindInPath:
+			if s, err := c.UserValueStr(f.name); err == nil {
+				values = []string{s}
+			}
+		case BindInQuery:
+			for _, value := range c.QueryArgs().PeekMulti(f.name) {
+				values = append(values, string(value))
+			}
+		case BindInHeader:
+			for _, value := range c.Request.Header.PeekAll(f.name) {
+				values = append(values, string(value))
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+		if err := setBindValue(field, values); err != nil {
+			fail(f.name, f.in, bindRuleType, err.Error())
+		}
+	}
+
+	// Validate, skipping fields that failed binding
+	if err := validate.Struct(v, validate.WithNameTags(bindNameTags...)); err != nil {
+		var e transport.Error
+		if !errors.As(err, &e) {
+			return err
+		}
+		for _, d := range e.GetDetails() {
+			if slices.ContainsFunc(details, func(b transport.FieldError) bool {
+				return b.Field == d.Field || b.Field == BindInBody
+			}) {
+				continue
+			}
+			name, _, _ := strings.Cut(d.Field, ".")
+			name, _, _ = strings.Cut(name, "[")
+			for _, f := range info.fields {
+				if f.name == name {
+					d.In = f.in
+				}
+			}
+			details = append(details, d)
+		}
+	}
+
+	if len(details) > 0 {
+		return transport.NewErrorWithDetails(
+			transport.ErrBadRequest,
+			"invalid request",
+			bindErrorCode,
+			details,
+		)
+	}
+
+	if v, ok := v.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// bindErrors returns the errors Bind may return for the type t, documented
+// by the routes binding requests.
+func bindErrors(t reflect.Type) []error {
+	errs := []error{
+		transport.NewError(transport.ErrBadRequest, "invalid request", bindErrorCode),
+	}
+	if t.Kind() == reflect.Struct && getBindInfo(t).body {
+		errs = append(errs, transport.ErrUnsupportedMediaType)
+	}
+	return errs
+}
+
+// getBindInfo returns the cached binding fields of the struct type t.
+func getBindInfo(t reflect.Type) *bindInfo {
+	if info, ok := bindInfoCache.Load(t); ok {
+		return info.(*bindInfo)
+	}
+	info := &bindInfo{}
+	addBindFields(info, t, nil)
+	bindInfoCache.Store(t, info)
+	return info
+}
+
+func addBindFields(info *bindInfo, t reflect.Type, index []int) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+
+		field := bindField{
+			index: idx,
+			def:   f.Tag.Get("default"),
+		}
+		for _, in := range []string{BindInPath, BindInQuery, BindInHeader} {
+			if name := f.Tag.Get(in); name != "" {
+				field.name, field.in = name, in
+				break
+			}
+		}
+
+		// Unexported fields cannot be set
+		if field.in != "" && !f.IsExported() {
+			continue
+		}
+
+		if field.in == "" {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+				addBindFields(info, f.Type, idx)
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			field.name, field.in = name, BindInBody
+			info.body = true
+		}
+
+		info.fields = append(info.fields, field)
+	}
+}
+
+// setBindValue sets v from the string values of a request parameter.
+func setBindValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setBindValue(v.Elem(), values)
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 &&
+		!reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		items := []string{}
+		for _, value := range values {
+			for item := range strings.SplitSeq(value, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setBindValue(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	s := values[0]
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q", s)
+		}
+		return nil
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("must be a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
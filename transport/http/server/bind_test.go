@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	fasthttpRouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"go.microcore.dev/framework/transport"
+)
+
+// routeHandler returns the router handler of a single route.
+func routeHandler(opts ...RouteOption) fasthttp.RequestHandler {
+	router := fasthttpRouter.New()
+	applyRoute(router, newRawRoute(opts...))
+	return router.Handler
+}
+
+// request serves a request with h. headers are name and value pairs.
+func request(h fasthttp.RequestHandler, method, uri string, body []byte, headers ...string) *fasthttp.Response {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for i := 0; i+1 < len(headers); i += 2 {
+		ctx.Request.Header.Add(headers[i], headers[i+1])
+	}
+	if body != nil {
+		ctx.Request.SetBody(body)
+	}
+	h(&ctx)
+	resp := &fasthttp.Response{}
+	ctx.Response.CopyTo(resp)
+	return resp
+}
+
+func errResponse(t *testing.T, resp *fasthttp.Response) ErrResponse {
+	t.Helper()
+	var e ErrResponse
+	if err := json.Unmarshal(resp.Body(), &e); err != nil {
+		t.Fatalf("invalid error response %q: %v", resp.Body(), err)
+	}
+	return e
+}
+
+type bindRequest struct {
+	Group   int           `path:"group"`
+	Limit   int           `query:"limit" default:"20"`
+	Tags    []string      `query:"tag"`
+	Timeout time.Duration `query:"timeout"`
+	Tenant  string        `header:"X-Tenant" validate:"required"`
+	Name    string        `json:"name"`
+
+	// Unexported fields are not bound
+	secret string `query:"secret"`
+}
+
+func TestBind(t *testing.T) {
+	var got bindRequest
+	h := routeHandler(
+		WithRouteMethod("POST"),
+		WithRoutePath("/groups/{group}"),
+		WithRouteBindHandler(func(_ context.Context, c *RequestContext, req *bindRequest) {
+			got = *req
+			c.StatusCode(204)
+		}),
+	)
+
+	resp := request(
+		h, "POST", "/groups/7?tag=a,b&tag=c&timeout=1s&secret=x", []byte(`{"name":"n"}`),
+		"X-Tenant", "t1", "Content-Type", "application/json",
+	)
+	if resp.StatusCode() != 204 {
+		t.Fatalf("status: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	want := bindRequest{Group: 7, Limit: 20, Tags: []string{"a", "b", "c"}, Timeout: time.Second, Tenant: "t1", Name: "n"}
+	if got.Group != want.Group || got.Limit != want.Limit || len(got.Tags) != 3 || got.Tags[2] != "c" ||
+		got.Timeout != want.Timeout || got.Tenant != want.Tenant || got.Name != want.Name || got.secret != "" {
+		t.Errorf("bound: got %+v, want %+v", got, want)
+	}
+
+	// Every offending field is reported
+	resp = request(h, "POST", "/groups/x?limit=y", nil)
+	if resp.StatusCode() != 400 {
+		t.Fatalf("invalid request status: got %d", resp.StatusCode())
+	}
+	e := errResponse(t, resp)
+	if e.Code != bindErrorCode || len(e.Details) != 3 {
+		t.Fatalf("invalid request: got %+v", e)
+	}
+	for i, want := range []transport.FieldError{
+		{Field: "group", In: BindInPath, Rule: bindRuleType},
+		{Field: "limit", In: BindInQuery, Rule: bindRuleType},
+		{Field: "X-Tenant", In: BindInHeader, Rule: "required"},
+	} {
+		if d := e.Details[i]; d.Field != want.Field || d.In != want.In || d.Rule != want.Rule {
+			t.Errorf("detail %d: got %+v, want %+v", i, d, want)
+		}
+	}
+
+	// Body type errors name the field
+	resp = request(h, "POST", "/groups/1", []byte(`{"name":1}`), "X-Tenant", "t1", "Content-Type", "application/json")
+	if e := errResponse(t, resp); len(e.Details) != 1 || e.Details[0].Field != "name" || e.Details[0].In != BindInBody {
+		t.Errorf("body type error: got %+v", e)
+	}
+}
+
+func TestBindNotStruct(t *testing.T) {
+	var ctx fasthttp.RequestCtx
+	c := &RequestContext{RequestCtx: &ctx}
+	var s string
+	if err := c.Bind(&s); err == nil {
+		t.Error("expected an error binding a string")
+	}
+}
@@ -0,0 +1,206 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CompressionLevel is the compression level of all algorithms, mapped to
+// the levels of each of them.
+type CompressionLevel int
+
+const (
+	CompressionLevelDefault CompressionLevel = iota
+	CompressionLevelBestSpeed
+	CompressionLevelBestCompression
+)
+
+type compression struct {
+	algorithms     []string
+	level          CompressionLevel
+	minSize        int
+	contentTypes   []string
+	decompress     bool
+	maxRequestSize int
+}
+
+// UseCompression compresses the responses of all routes, see
+// CompressionMiddleware.
+func (s *server) UseCompression(opts ...CompressionOption) Manager {
+	return s.AddMiddleware(CompressionMiddleware(opts...))
+}
+
+// CompressionMiddleware returns a middleware compressing responses with the
+// algorithm negotiated from the Accept-Encoding header, among gzip, br and
+// zstd in order of preference by default. It may be added to the whole
+// server with UseCompression, or to routes and route groups.
+//
+// Responses are compressed if their body is at least
+// DefaultCompressionMinSize bytes and their media type is in the allow list
+// (text/*, JSON, XML, JavaScript and SVG by default). Streamed bodies,
+// responses already encoded and responses with Cache-Control: no-transform
+// are left unchanged. Strong ETags of compressed responses are made weak, as
+// they no longer match the bytes sent.
+//
+// Request bodies with Content-Encoding: gzip are decompressed before the
+// handler is called, up to DefaultCompressionMaxRequestSize bytes.
+//
+// Example:
+//
+//	server.WithRouteMiddlewares(server.CompressionMiddleware(
+//	    server.WithCompressionAlgorithms(server.CompressionGzip),
+//	    server.WithCompressionLevel(server.CompressionLevelBestSpeed),
+//	))
+func CompressionMiddleware(opts ...CompressionOption) MiddlewareHandler {
+	comp := &compression{
+		algorithms:     []string{CompressionGzip, CompressionBrotli, CompressionZstd},
+		level:          CompressionLevelDefault,
+		minSize:        DefaultCompressionMinSize,
+		contentTypes:   defaultCompressionContentTypes,
+		decompress:     true,
+		maxRequestSize: DefaultCompressionMaxRequestSize,
+	}
+
+	for _, opt := range opts {
+		opt(comp)
+	}
+
+	return func(next RequestHandler) RequestHandler {
+		return func(c *RequestContext) {
+			if comp.decompress && strings.EqualFold(c.GetHeaderStr("Content-Encoding"), CompressionGzip) {
+				if err := comp.decompressRequest(c); err != nil {
+					c.WriteError(err)
+					return
+				}
+			}
+
+			next(c)
+
+			comp.compressResponse(c)
+		}
+	}
+}
+
+func (comp *compression) decompressRequest(c *RequestContext) error {
+	zr, err := gzip.NewReader(bytes.NewReader(c.Request.Body()))
+	if err != nil {
+		return transport.NewError(transport.ErrBadRequest, "invalid gzip body", "INVALID_GZIP_BODY")
+	}
+	defer zr.Close()
+
+	body, err := io.ReadAll(io.LimitReader(zr, int64(comp.maxRequestSize)+1))
+	if err != nil {
+		return transport.NewError(transport.ErrBadRequest, "invalid gzip body", "INVALID_GZIP_BODY")
+	}
+	if len(body) > comp.maxRequestSize {
+		return transport.NewError(
+			transport.ErrRequestEntityTooLarge,
+			"decompressed body exceeds "+strconv.Itoa(comp.maxRequestSize)+" bytes",
+			"REQUEST_ENTITY_TOO_LARGE",
+		)
+	}
+
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.SetBody(body)
+	return nil
+}
+
+func (comp *compression) compressResponse(c *RequestContext) {
+	resp := &c.Response
+
+	status := http.StatusCode(resp.StatusCode())
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		c.IsHead() || resp.IsBodyStream() ||
+		len(resp.Header.ContentEncoding()) > 0 ||
+		!comp.compressible(string(resp.Header.ContentType())) {
+		return
+	}
+
+	// The representation depends on Accept-Encoding even when not compressed
+	resp.Header.Add("Vary", "Accept-Encoding")
+
+	if len(resp.Body()) < comp.minSize ||
+		strings.Contains(strings.ToLower(string(resp.Header.Peek("Cache-Control"))), "no-transform") {
+		return
+	}
+
+	algorithm := negotiateEncoding(c.GetHeaderStr("Accept-Encoding"), comp.algorithms)
+
+	var body []byte
+	switch algorithm {
+	case CompressionGzip:
+		body = fasthttp.AppendGzipBytesLevel(nil, resp.Body(), gzipLevels[comp.level])
+	case CompressionBrotli:
+		body = fasthttp.AppendBrotliBytesLevel(nil, resp.Body(), brotliLevels[comp.level])
+	case CompressionZstd:
+		body = fasthttp.AppendZstdBytesLevel(nil, resp.Body(), zstdLevels[comp.level])
+	default:
+		return
+	}
+
+	resp.SetBodyRaw(body)
+	resp.Header.SetContentEncoding(algorithm)
+
+	// A strong ETag identifies the uncompressed bytes
+	if etag := resp.Header.Peek("ETag"); len(etag) > 0 && !bytes.HasPrefix(etag, []byte("W/")) {
+		resp.Header.Set("ETag", "W/"+string(etag))
+	}
+}
+
+// compressible reports whether the media type of contentType matches the
+// allow list, where type/* matches all subtypes of type.
+func (comp *compression) compressible(contentType string) bool {
+	mediaType := codec.MediaType(contentType)
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	return slices.ContainsFunc(comp.contentTypes, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		return allowed == mediaType || allowed == mainType+"/*"
+	})
+}
+
+// negotiateEncoding returns the algorithm with the highest quality in the
+// Accept-Encoding header, preferring earlier algorithms on ties, or "" if
+// none is acceptable.
+func negotiateEncoding(acceptEncoding string, algorithms []string) string {
+	qualities := map[string]float64{}
+	for part := range strings.SplitSeq(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if k == "q" {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, algorithm := range algorithms {
+		q, ok := qualities[algorithm]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = algorithm, q
+		}
+	}
+	return best
+}
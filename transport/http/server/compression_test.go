@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func compressionHandler(body string, contentType string, opts ...CompressionOption) fasthttp.RequestHandler {
+	h := CompressionMiddleware(opts...)(func(c *RequestContext) {
+		c.SetContentType(contentType)
+		c.WriteString(body)
+	})
+	return func(ctx *fasthttp.RequestCtx) {
+		h(&RequestContext{RequestCtx: ctx})
+	}
+}
+
+func TestCompressionResponse(t *testing.T) {
+	large := strings.Repeat("compressible ", 200)
+
+	for _, tt := range []struct {
+		name           string
+		body           string
+		contentType    string
+		acceptEncoding string
+		opts           []CompressionOption
+		encoding       string
+	}{
+		{"gzip", large, "application/json", "gzip", nil, CompressionGzip},
+		{"preferred", large, "text/plain", "gzip, br, zstd", nil, CompressionGzip},
+		{"quality", large, "text/plain", "gzip;q=0.5, br", nil, CompressionBrotli},
+		{"wildcard", large, "text/html", "*", nil, CompressionGzip},
+		{"zstd", large, "text/plain", "zstd", nil, CompressionZstd},
+		{"not acceptable", large, "text/plain", "gzip;q=0", nil, ""},
+		{"small", "small", "text/plain", "gzip", nil, ""},
+		{"content type", large, "image/png", "gzip", nil, ""},
+		{"algorithms", large, "text/plain", "br", []CompressionOption{WithCompressionAlgorithms(CompressionGzip)}, ""},
+		{"best speed", large, "text/plain", "gzip", []CompressionOption{WithCompressionLevel(CompressionLevelBestSpeed)}, CompressionGzip},
+		// Invalid levels are ignored, they would disable gzip compression
+		{"invalid level", large, "text/plain", "gzip", []CompressionOption{WithCompressionLevel(42)}, CompressionGzip},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := request(compressionHandler(tt.body, tt.contentType, tt.opts...), "GET", "/", nil, "Accept-Encoding", tt.acceptEncoding)
+			if got := string(resp.Header.ContentEncoding()); got != tt.encoding {
+				t.Fatalf("encoding: got %q, want %q", got, tt.encoding)
+			}
+			if tt.encoding != "" && len(resp.Body()) >= len(tt.body) {
+				t.Errorf("body not compressed: %d bytes", len(resp.Body()))
+			}
+			body, err := resp.BodyUncompressed()
+			if err != nil || string(body) != tt.body {
+				t.Errorf("uncompressed body: got %d bytes (%v)", len(body), err)
+			}
+			if tt.contentType != "image/png" && !slices.Contains(strings.Split(string(resp.Header.Peek("Vary")), ", "), "Accept-Encoding") {
+				t.Errorf("vary: got %q", resp.Header.Peek("Vary"))
+			}
+		})
+	}
+
+	// Cache-Control: no-transform
+	h := CompressionMiddleware()(func(c *RequestContext) {
+		c.Response.Header.Set("Cache-Control", "no-transform")
+		c.SetContentType("text/plain")
+		c.WriteString(large)
+	})
+	resp := request(func(ctx *fasthttp.RequestCtx) { h(&RequestContext{RequestCtx: ctx}) }, "GET", "/", nil, "Accept-Encoding", "gzip")
+	if len(resp.Header.ContentEncoding()) > 0 {
+		t.Errorf("no-transform: got encoding %q", resp.Header.ContentEncoding())
+	}
+}
+
+func TestCompressionETag(t *testing.T) {
+	large := strings.Repeat("compressible ", 200)
+
+	for etag, want := range map[string]string{
+		`"v1"`:   `W/"v1"`,
+		`W/"v1"`: `W/"v1"`,
+		"":       "",
+	} {
+		h := CompressionMiddleware()(func(c *RequestContext) {
+			if etag != "" {
+				c.Response.Header.Set("ETag", etag)
+			}
+			c.SetContentType("text/plain")
+			c.WriteString(large)
+		})
+		handler := func(ctx *fasthttp.RequestCtx) { h(&RequestContext{RequestCtx: ctx}) }
+
+		resp := request(handler, "GET", "/", nil, "Accept-Encoding", "gzip")
+		if got := string(resp.Header.Peek("ETag")); got != want {
+			t.Errorf("%s: got ETag %q, want %q", etag, got, want)
+		}
+
+		// Uncompressed responses keep their ETag
+		resp = request(handler, "GET", "/", nil)
+		if got := string(resp.Header.Peek("ETag")); got != etag {
+			t.Errorf("%s uncompressed: got ETag %q", etag, got)
+		}
+	}
+}
+
+func TestCompressionRequest(t *testing.T) {
+	var got []byte
+	h := CompressionMiddleware(WithCompressionMaxRequestSize(10))(func(c *RequestContext) {
+		got = append([]byte{}, c.Request.Body()...)
+	})
+	handler := func(ctx *fasthttp.RequestCtx) { h(&RequestContext{RequestCtx: ctx}) }
+
+	gz := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		io.WriteString(zw, s)
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	request(handler, "POST", "/", gz("hello"), "Content-Encoding", "gzip")
+	if string(got) != "hello" {
+		t.Errorf("decompressed body: got %q", got)
+	}
+
+	if resp := request(handler, "POST", "/", gz("hello world!"), "Content-Encoding", "gzip"); resp.StatusCode() != 413 {
+		t.Errorf("too large: got %d", resp.StatusCode())
+	}
+	if resp := request(handler, "POST", "/", []byte("plain"), "Content-Encoding", "gzip"); resp.StatusCode() != 400 {
+		t.Errorf("invalid gzip: got %d", resp.StatusCode())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	_ "go.microcore.dev/framework"
 	"go.microcore.dev/framework/transport"
 	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
 )
 
 const (
@@ -20,6 +21,19 @@ const (
 	DefaultCorsMethods = "*"
 	DefaultCorsHeaders = "*"
 
+	DefaultProblemType     = "about:blank"
+	ContentTypeProblemJson = "application/problem+json"
+
+	DefaultOpenAPIPath   = "/openapi.json"
+	DefaultOpenAPIUIPath = "/docs"
+
+	CompressionGzip   = "gzip"
+	CompressionBrotli = "br"
+	CompressionZstd   = "zstd"
+
+	DefaultCompressionMinSize        = 1024
+	DefaultCompressionMaxRequestSize = 4 * 1024 * 1024
+
 	DefaultShutdownTimeout = 10 * time.Second
 	DefaultShutdownHandler = true
 )
@@ -27,6 +41,44 @@ const (
 var (
 	defaultResponseErr  = transport.ErrServiceUnavailable
 	defaultResponseCode = "SERVICE_UNAVAILABLE"
+
+	bindErrorCode = "INVALID_REQUEST"
+
+	defaultCompressionContentTypes = []string{
+		"text/*",
+		"application/json",
+		"application/problem+json",
+		"application/xml",
+		"application/javascript",
+		"image/svg+xml",
+	}
+
+	// Levels of each algorithm by CompressionLevel
+	gzipLevels = map[CompressionLevel]int{
+		CompressionLevelDefault:         fasthttp.CompressDefaultCompression,
+		CompressionLevelBestSpeed:       fasthttp.CompressBestSpeed,
+		CompressionLevelBestCompression: fasthttp.CompressBestCompression,
+	}
+	brotliLevels = map[CompressionLevel]int{
+		CompressionLevelDefault:         fasthttp.CompressBrotliDefaultCompression,
+		CompressionLevelBestSpeed:       fasthttp.CompressBrotliBestSpeed,
+		CompressionLevelBestCompression: fasthttp.CompressBrotliBestCompression,
+	}
+	zstdLevels = map[CompressionLevel]int{
+		CompressionLevelDefault:         fasthttp.CompressZstdDefault,
+		CompressionLevelBestSpeed:       fasthttp.CompressZstdBestSpeed,
+		CompressionLevelBestCompression: fasthttp.CompressZstdBestCompression,
+	}
+
+	// Codecs of requests handled outside a server, e.g. in tests
+	defaultCodecs = codec.NewRegistry()
+)
+
+// User value keys of the server settings read by RequestContext, which
+// cannot collide with route path parameters
+type (
+	errorRendererKey struct{}
+	codecsKey        struct{}
 )
 
 func defaultRouteHandler(c *RequestContext) {
@@ -0,0 +1,180 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport/http"
+)
+
+func newCors(opts ...CorsOption) *cors {
+	cors := &cors{
+		origins: []string{DefaultCorsOrigin},
+		methods: DefaultCorsMethods,
+		headers: DefaultCorsHeaders,
+	}
+
+	for _, opt := range opts {
+		opt(cors)
+	}
+
+	// Any site could make credentialed requests
+	if cors.credentials && cors.allowAll() {
+		logger.Warn("cors credentials ignored, origins allow all")
+		cors.credentials = false
+	}
+
+	return cors
+}
+
+// CorsMiddleware returns a middleware handling Cross-Origin Resource
+// Sharing. It may be added to routes, route groups or the whole server
+// with AddMiddleware, but preflight requests only reach it if an OPTIONS
+// route matches, see UseCors and WithRouteGroupCors.
+//
+// Requests from allowed origins get the Access-Control-Allow-Origin header,
+// along with Access-Control-Allow-Credentials and
+// Access-Control-Expose-Headers if configured. Preflight requests are
+// answered with 204 No Content and the allowed methods, headers and max
+// age without calling the handler. Responses that depend on the origin
+// have a Vary: Origin header.
+//
+// Example:
+//
+//	server.CorsMiddleware(
+//	    server.WithCorsOrigins("https://example.com", "https://*.example.com"),
+//	    server.WithCorsCredentials(),
+//	    server.WithCorsExposedHeaders("X-Trace-Id"),
+//	    server.WithCorsMaxAge(10*time.Minute),
+//	)
+func CorsMiddleware(opts ...CorsOption) MiddlewareHandler {
+	return newCors(opts...).middleware
+}
+
+func (cors *cors) middleware(next RequestHandler) RequestHandler {
+	return func(c *RequestContext) {
+		origin := c.GetHeaderStr("Origin")
+		preflight := c.IsOptions() && origin != "" && c.GetHeaderStr("Access-Control-Request-Method") != ""
+
+		if preflight {
+			c.Response.Reset()
+			c.Response.Header.Add("Vary", "Origin")
+			c.Response.Header.Add("Vary", "Access-Control-Request-Method")
+			c.Response.Header.Add("Vary", "Access-Control-Request-Headers")
+			if cors.allowed(origin) {
+				cors.setOriginHeaders(c, origin)
+				c.Response.Header.Set("Access-Control-Allow-Methods", cors.allowedMethods(c))
+				if headers := cors.allowedHeaders(c); headers != "" {
+					c.Response.Header.Set("Access-Control-Allow-Headers", headers)
+				}
+				if cors.maxAge > 0 {
+					c.Response.Header.Set("Access-Control-Max-Age", strconv.Itoa(int(cors.maxAge.Seconds())))
+				}
+			}
+			c.StatusCode(http.StatusNoContent)
+			return
+		}
+
+		next(c)
+
+		// Set after the handler, which may reset the response
+		if !cors.allowAll() {
+			c.Response.Header.Add("Vary", "Origin")
+		}
+		if origin == "" || !cors.allowed(origin) {
+			return
+		}
+		cors.setOriginHeaders(c, origin)
+		if cors.exposedHeaders != "" {
+			c.Response.Header.Set("Access-Control-Expose-Headers", cors.exposedHeaders)
+		}
+	}
+}
+
+func (cors *cors) setOriginHeaders(c *RequestContext, origin string) {
+	if cors.allowAll() {
+		c.Response.Header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Response.Header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if cors.credentials {
+		c.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowedMethods returns the allowed methods of a preflight request. With
+// credentials, * is not a wildcard, so the requested method is allowed.
+func (cors *cors) allowedMethods(c *RequestContext) string {
+	if cors.methods == "*" && cors.credentials {
+		return c.GetHeaderStr("Access-Control-Request-Method")
+	}
+	return cors.methods
+}
+
+// allowedHeaders returns the allowed headers of a preflight request, like
+// allowedMethods.
+func (cors *cors) allowedHeaders(c *RequestContext) string {
+	if cors.headers == "*" && cors.credentials {
+		return c.GetHeaderStr("Access-Control-Request-Headers")
+	}
+	return cors.headers
+}
+
+func (cors *cors) allowAll() bool {
+	return slices.Contains(cors.origins, "*")
+}
+
+// allowed reports whether origin matches an allowed origin, where * in an
+// allowed origin matches one or more characters, or a pattern.
+func (cors *cors) allowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range cors.origins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	for _, pattern := range cors.originPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeGroupCorsRoutes returns the routes of routeGroup and its nested groups
+// that have a CORS policy of their group, with an OPTIONS route per path for
+// their preflight requests. Handlers and documentation are not set.
+func routeGroupCorsRoutes(prefix string, routeGroup *routeGroup, cors bool) []rawRoute {
+	if prefix == "" || routeGroup.path != "/" {
+		prefix += routeGroup.path
+	}
+	cors = cors || routeGroup.cors != nil
+
+	routes := []rawRoute{}
+	if cors {
+		for _, route := range routeGroup.rawRoutes {
+			path := route.path
+			if prefix != "/" {
+				path = prefix + path
+			}
+			routes = append(routes,
+				rawRoute{method: route.method, path: path},
+				rawRoute{method: http.MethodOptions, path: path},
+			)
+		}
+	}
+
+	for _, g := range routeGroup.routeGroups {
+		routes = append(routes, routeGroupCorsRoutes(prefix, g, cors)...)
+	}
+
+	return routes
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	fasthttpRouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+)
+
+func corsRequest(h fasthttp.RequestHandler, method, path, origin string, preflight bool) *fasthttp.Response {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(path)
+	if origin != "" {
+		ctx.Request.Header.Set("Origin", origin)
+	}
+	if preflight {
+		ctx.Request.Header.Set("Access-Control-Request-Method", "PUT")
+		ctx.Request.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	}
+	h(&ctx)
+	resp := &fasthttp.Response{}
+	ctx.Response.CopyTo(resp)
+	return resp
+}
+
+func vary(resp *fasthttp.Response) []string {
+	values := []string{}
+	for _, v := range resp.Header.PeekAll("Vary") {
+		values = append(values, string(v))
+	}
+	return values
+}
+
+func corsRouter(opts ...RouteGroupOption) fasthttp.RequestHandler {
+	router := fasthttpRouter.New()
+	applyRouteGroup(router, nil, newRouteGroup(opts...), []MiddlewareHandler{}, nil)
+	return router.Handler
+}
+
+func okRoute(method, path string) RouteGroupOption {
+	return WithRouteGroupRoute(
+		WithRouteMethod(method),
+		WithRoutePath(path),
+		WithRouteHandler(func(_ context.Context, c *RequestContext) {
+			c.WriteString("ok")
+		}),
+	)
+}
+
+func TestCorsPreflight(t *testing.T) {
+	called := false
+	h := CorsMiddleware(
+		WithCorsOrigins("https://app.example.com"),
+		WithCorsMethods("GET, PUT"),
+		WithCorsHeaders("X-Custom"),
+		WithCorsMaxAge(600_000_000_000),
+	)(func(c *RequestContext) {
+		called = true
+	})
+	handler := func(ctx *fasthttp.RequestCtx) { h(&RequestContext{RequestCtx: ctx}) }
+
+	resp := corsRequest(handler, "OPTIONS", "/", "https://app.example.com", true)
+	if called {
+		t.Error("preflight reached the handler")
+	}
+	if resp.StatusCode() != fasthttp.StatusNoContent {
+		t.Errorf("status: got %d", resp.StatusCode())
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, PUT",
+		"Access-Control-Allow-Headers": "X-Custom",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := string(resp.Header.Peek(header)); got != want {
+			t.Errorf("%s: got %q, want %q", header, got, want)
+		}
+	}
+	if !slices.Contains(vary(resp), "Origin") {
+		t.Errorf("vary: got %v", vary(resp))
+	}
+
+	// Disallowed origins get no CORS headers
+	resp = corsRequest(handler, "OPTIONS", "/", "https://evil.com", true)
+	if called || len(resp.Header.Peek("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("disallowed preflight: called %v, headers %s", called, resp.Header.String())
+	}
+
+	// OPTIONS requests without Access-Control-Request-Method are not
+	// preflights
+	corsRequest(handler, "OPTIONS", "/", "https://app.example.com", false)
+	if !called {
+		t.Error("plain OPTIONS request did not reach the handler")
+	}
+}
+
+func TestCorsOrigins(t *testing.T) {
+	h := corsRouter(
+		WithRouteGroupPath("/api"),
+		WithRouteGroupCors(
+			WithCorsOrigins("https://*.example.com"),
+			WithCorsOriginPatterns(regexp.MustCompile(`^http://localhost:\d+$`)),
+			WithCorsCredentials(),
+			WithCorsExposedHeaders("X-Trace-Id"),
+		),
+		okRoute("GET", "/x"),
+	)
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.com":  true,
+		"https://APP.example.com":  true,
+		"http://localhost:3000":    true,
+		"https://example.com":      false,
+		"https://example.com.evil": false,
+		"http://app.example.com":   false,
+	} {
+		resp := corsRequest(h, "GET", "/api/x", origin, false)
+		if string(resp.Body()) != "ok" {
+			t.Errorf("%s: got body %q", origin, resp.Body())
+		}
+		if !slices.Contains(vary(resp), "Origin") {
+			t.Errorf("%s: vary: got %v", origin, vary(resp))
+		}
+		got := string(resp.Header.Peek("Access-Control-Allow-Origin"))
+		if allowed && (got != origin ||
+			string(resp.Header.Peek("Access-Control-Allow-Credentials")) != "true" ||
+			string(resp.Header.Peek("Access-Control-Expose-Headers")) != "X-Trace-Id") {
+			t.Errorf("%s: got headers %s", origin, resp.Header.String())
+		}
+		if !allowed && got != "" {
+			t.Errorf("%s: got allowed origin %q", origin, got)
+		}
+	}
+
+	// Requests without Origin are not CORS requests
+	resp := corsRequest(h, "GET", "/api/x", "", false)
+	if len(resp.Header.Peek("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("no origin: got headers %s", resp.Header.String())
+	}
+}
+
+func TestCorsWildcard(t *testing.T) {
+	h := corsRouter(WithRouteGroupPath("/api"), WithRouteGroupCors(), okRoute("GET", "/x"))
+
+	resp := corsRequest(h, "GET", "/api/x", "https://any.com", false)
+	if got := string(resp.Header.Peek("Access-Control-Allow-Origin")); got != "*" {
+		t.Errorf("allow origin: got %q", got)
+	}
+	if slices.Contains(vary(resp), "Origin") {
+		t.Errorf("vary: got %v", vary(resp))
+	}
+
+	// Credentials are ignored if all origins are allowed
+	h = corsRouter(WithRouteGroupPath("/api"), WithRouteGroupCors(WithCorsCredentials()), okRoute("GET", "/x"))
+	resp = corsRequest(h, "GET", "/api/x", "https://any.com", false)
+	if string(resp.Header.Peek("Access-Control-Allow-Origin")) != "*" ||
+		len(resp.Header.Peek("Access-Control-Allow-Credentials")) > 0 {
+		t.Errorf("credentials with wildcard: got headers %s", resp.Header.String())
+	}
+}
+
+func TestCorsRouteGroups(t *testing.T) {
+	h := corsRouter(
+		WithRouteGroupPath("/api"),
+		WithRouteGroupCors(
+			WithCorsOrigins("https://a.example.com", "https://b.example.com"),
+			WithCorsExposedHeaders("X-Parent"),
+		),
+		okRoute("GET", "/x"),
+		okRoute("POST", "/x"),
+		WithRouteGroup(
+			WithRouteGroupPath("/inherited"),
+			okRoute("GET", "/y"),
+		),
+		WithRouteGroup(
+			WithRouteGroupPath("/nested"),
+			WithRouteGroupCors(
+				WithCorsOrigins("https://b.example.com"),
+				WithCorsMethods("PUT"),
+				WithCorsExposedHeaders("X-Child"),
+			),
+			okRoute("PUT", "/z"),
+		),
+		WithRouteGroup(
+			WithRouteGroupPath("/custom"),
+			okRoute("GET", "/w"),
+			WithRouteGroupRoute(
+				WithRouteMethod("OPTIONS"),
+				WithRoutePath("/w"),
+				WithRouteHandler(func(_ context.Context, c *RequestContext) {
+					c.WriteString("custom")
+				}),
+			),
+		),
+	)
+
+	// Preflights of group routes are short-circuited, once per path
+	for _, path := range []string{"/api/x", "/api/inherited/y"} {
+		resp := corsRequest(h, "OPTIONS", path, "https://a.example.com", true)
+		if resp.StatusCode() != fasthttp.StatusNoContent ||
+			string(resp.Header.Peek("Access-Control-Allow-Origin")) != "https://a.example.com" {
+			t.Errorf("%s preflight: got %d %s", path, resp.StatusCode(), resp.Header.String())
+		}
+	}
+
+	// Nested groups replace the inherited CORS handling
+	resp := corsRequest(h, "OPTIONS", "/api/nested/z", "https://a.example.com", true)
+	if len(resp.Header.Peek("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("nested preflight of parent origin: got headers %s", resp.Header.String())
+	}
+	resp = corsRequest(h, "OPTIONS", "/api/nested/z", "https://b.example.com", true)
+	if string(resp.Header.Peek("Access-Control-Allow-Methods")) != "PUT" {
+		t.Errorf("nested preflight: got headers %s", resp.Header.String())
+	}
+	resp = corsRequest(h, "PUT", "/api/nested/z", "https://b.example.com", false)
+	if got := string(resp.Header.Peek("Access-Control-Expose-Headers")); got != "X-Child" {
+		t.Errorf("nested expose headers: got %q", got)
+	}
+	if got := vary(resp); len(got) != 1 {
+		t.Errorf("nested vary: got %v", got)
+	}
+
+	// Routes keep their own OPTIONS handlers
+	resp = corsRequest(h, "OPTIONS", "/api/custom/w", "https://a.example.com", false)
+	if string(resp.Body()) != "custom" {
+		t.Errorf("custom OPTIONS route: got %q", resp.Body())
+	}
+}
+
+func TestCorsGroupPrecedence(t *testing.T) {
+	s := &server{router: fasthttpRouter.New()}
+	s.UseCors(WithCorsOrigins("https://global.example.com"), WithCorsExposedHeaders("X-Global"))
+	s.AddRouteGroup(
+		WithRouteGroupPath("/api"),
+		WithRouteGroupCors(WithCorsOrigins("https://group.example.com"), WithCorsMethods("PUT")),
+		okRoute("GET", "/x/{id}"),
+	)
+	s.AddRouteGroup(WithRouteGroupPath("/public"), okRoute("GET", "/y"))
+
+	h := s.router.Handler
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+
+	for _, tt := range []struct {
+		name, method, path, origin string
+		preflight                  bool
+		allowOrigin                string
+	}{
+		{"group preflight", "OPTIONS", "/api/x/1", "https://group.example.com", true, "https://group.example.com"},
+		{"group preflight of global origin", "OPTIONS", "/api/x/1", "https://global.example.com", true, ""},
+		{"group request", "GET", "/api/x/1", "https://group.example.com", false, "https://group.example.com"},
+		{"group request of global origin", "GET", "/api/x/1", "https://global.example.com", false, ""},
+		{"global preflight", "OPTIONS", "/public/y", "https://global.example.com", true, "https://global.example.com"},
+		{"global request", "GET", "/public/y", "https://global.example.com", false, "https://global.example.com"},
+		{"global request of group origin", "GET", "/public/y", "https://group.example.com", false, ""},
+	} {
+		resp := corsRequest(h, tt.method, tt.path, tt.origin, tt.preflight)
+		if got := string(resp.Header.Peek("Access-Control-Allow-Origin")); got != tt.allowOrigin {
+			t.Errorf("%s: got allow origin %q, want %q", tt.name, got, tt.allowOrigin)
+		}
+		if tt.preflight && tt.allowOrigin != "" && resp.StatusCode() != fasthttp.StatusNoContent {
+			t.Errorf("%s: got status %d", tt.name, resp.StatusCode())
+		}
+		if !tt.preflight && string(resp.Body()) != "ok" {
+			t.Errorf("%s: got body %q", tt.name, resp.Body())
+		}
+	}
+
+	// The group policy alone sets the CORS headers of its routes
+	resp := corsRequest(h, "OPTIONS", "/api/x/1", "https://group.example.com", true)
+	if got := string(resp.Header.Peek("Access-Control-Allow-Methods")); got != "PUT" {
+		t.Errorf("group preflight methods: got %q", got)
+	}
+	resp = corsRequest(h, "GET", "/api/x/1", "https://group.example.com", false)
+	if got := vary(resp); len(got) != 1 || len(resp.Header.Peek("Access-Control-Expose-Headers")) > 0 {
+		t.Errorf("group request: got headers %s", resp.Header.String())
+	}
+}
@@ -0,0 +1,131 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+)
+
+type (
+	// LogLevelsRequest changes log levels. Empty fields are left unchanged.
+	// An empty package level removes the override. If TTL is set (for
+	// example "10m"), the changes revert automatically once it elapses.
+	LogLevelsRequest struct {
+		Level    string            `json:"level,omitempty"`
+		Packages map[string]string `json:"packages,omitempty"`
+		TTL      string            `json:"ttl,omitempty"`
+	}
+
+	LogLevelsResponse struct {
+		Level    LogLevel            `json:"level"`
+		Packages map[string]LogLevel `json:"packages"`
+	}
+
+	LogLevel struct {
+		Level   string     `json:"level"`
+		Expires *time.Time `json:"expires,omitempty"`
+	}
+)
+
+const logLevelsErrCode = "INVALID_LOG_LEVEL"
+
+func getLogLevels(c *RequestContext) {
+	c.WriteJsonWithStatusCode(http.StatusOK, newLogLevelsResponse())
+}
+
+func putLogLevels(c *RequestContext) {
+	var req LogLevelsRequest
+	if err := c.ReadJsonBody(&req); err != nil {
+		c.WriteError(transport.NewError(transport.ErrBadRequest, err.Error(), logLevelsErrCode))
+		return
+	}
+
+	// Validate everything before applying anything
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			c.WriteError(transport.NewError(transport.ErrBadRequest, fmt.Sprintf("invalid ttl %q", req.TTL), logLevelsErrCode))
+			return
+		}
+	}
+
+	var global *slog.Level
+	if req.Level != "" {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(req.Level)); err != nil {
+			c.WriteError(transport.NewError(transport.ErrBadRequest, err.Error(), logLevelsErrCode))
+			return
+		}
+		global = &l
+	}
+
+	packages := make(map[string]*slog.Level, len(req.Packages))
+	for prefix, value := range req.Packages {
+		if prefix == "" {
+			c.WriteError(transport.NewError(transport.ErrBadRequest, "empty package", logLevelsErrCode))
+			return
+		}
+		if value == "" {
+			packages[prefix] = nil
+			continue
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(value)); err != nil {
+			c.WriteError(transport.NewError(transport.ErrBadRequest, fmt.Sprintf("package %s: %v", prefix, err), logLevelsErrCode))
+			return
+		}
+		packages[prefix] = &l
+	}
+
+	if global != nil {
+		if ttl > 0 {
+			log.SetLevelTemporary(*global, ttl)
+		} else {
+			log.SetLevel(*global)
+		}
+	}
+	for prefix, l := range packages {
+		switch {
+		case l == nil:
+			log.UnsetPackageLevel(prefix)
+		case ttl > 0:
+			log.SetPackageLevelTemporary(prefix, *l, ttl)
+		default:
+			log.SetPackageLevel(prefix, *l)
+		}
+	}
+
+	logger.InfoContext(
+		c.GetContext(),
+		"log levels changed",
+		slog.String("level", req.Level),
+		slog.Any("packages", req.Packages),
+		slog.Duration("ttl", ttl),
+	)
+
+	c.WriteJsonWithStatusCode(http.StatusOK, newLogLevelsResponse())
+}
+
+func newLogLevelsResponse() LogLevelsResponse {
+	res := LogLevelsResponse{
+		Level:    LogLevel{Level: log.GetLevel().String()},
+		Packages: make(map[string]LogLevel),
+	}
+	if t, ok := log.GetLevelExpiry(); ok {
+		res.Level.Expires = &t
+	}
+	for prefix, l := range log.PackageLevels() {
+		level := LogLevel{Level: l.String()}
+		if t, ok := log.GetPackageLevelExpiry(prefix); ok {
+			level.Expires = &t
+		}
+		res.Packages[prefix] = level
+	}
+	return res
+}
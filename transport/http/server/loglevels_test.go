@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.microcore.dev/framework/log"
+
+	"github.com/valyala/fasthttp"
+)
+
+func logLevels(t *testing.T, method string, body string) (*fasthttp.Response, LogLevelsResponse) {
+	t.Helper()
+	h := getLogLevels
+	if method == "PUT" {
+		h = putLogLevels
+	}
+	var b []byte
+	if body != "" {
+		b = []byte(body)
+	}
+	resp := request(func(ctx *fasthttp.RequestCtx) {
+		h(&RequestContext{RequestCtx: ctx})
+	}, method, "/debug/log/levels", b, "Content-Type", "application/json")
+
+	var res LogLevelsResponse
+	if resp.StatusCode() == 200 {
+		if err := json.Unmarshal(resp.Body(), &res); err != nil {
+			t.Fatalf("invalid response %q: %v", resp.Body(), err)
+		}
+	}
+	return resp, res
+}
+
+func TestLogLevels(t *testing.T) {
+	t.Cleanup(log.SetDefaultState)
+	log.SetDefaultState()
+	log.SetPackageLevel("go.microcore.dev/framework/db", slog.LevelWarn)
+
+	_, res := logLevels(t, "GET", "")
+	if res.Level.Level != log.DefaultLogLevel.String() || res.Level.Expires != nil ||
+		res.Packages["go.microcore.dev/framework/db"].Level != "WARN" {
+		t.Errorf("get: got %+v", res)
+	}
+
+	_, res = logLevels(t, "PUT", `{"level":"debug","packages":{"go.microcore.dev/framework/db":"","go.microcore.dev/framework/auth":"error"}}`)
+	if res.Level.Level != "DEBUG" || len(res.Packages) != 1 || res.Packages["go.microcore.dev/framework/auth"].Level != "ERROR" {
+		t.Errorf("put: got %+v", res)
+	}
+	if log.GetLevel() != slog.LevelDebug {
+		t.Errorf("put: got level %s", log.GetLevel())
+	}
+}
+
+func TestLogLevelsExpiry(t *testing.T) {
+	t.Cleanup(log.SetDefaultState)
+	log.SetDefaultState()
+
+	_, res := logLevels(t, "PUT", `{"level":"debug","packages":{"go.microcore.dev/framework/auth":"error"},"ttl":"100ms"}`)
+	if res.Level.Level != "DEBUG" || res.Level.Expires == nil ||
+		res.Packages["go.microcore.dev/framework/auth"].Expires == nil {
+		t.Fatalf("put: got %+v", res)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	_, res = logLevels(t, "GET", "")
+	if res.Level.Level != log.DefaultLogLevel.String() || res.Level.Expires != nil || len(res.Packages) != 0 {
+		t.Errorf("expired: got %+v", res)
+	}
+}
+
+func TestLogLevelsInvalid(t *testing.T) {
+	t.Cleanup(log.SetDefaultState)
+	log.SetDefaultState()
+
+	for name, body := range map[string]string{
+		"level":         `{"level":"verbose","packages":{"go.microcore.dev/framework/auth":"error"}}`,
+		"package level": `{"level":"debug","packages":{"go.microcore.dev/framework/auth":"verbose"}}`,
+		"empty package": `{"packages":{"":"debug"}}`,
+		"ttl":           `{"level":"debug","ttl":"soon"}`,
+		"negative ttl":  `{"level":"debug","ttl":"-1m"}`,
+		"body":          `{"level":`,
+	} {
+		resp, _ := logLevels(t, "PUT", body)
+		if resp.StatusCode() != 400 || errResponse(t, resp).Code != logLevelsErrCode {
+			t.Errorf("%s: got %d %s", name, resp.StatusCode(), resp.Body())
+		}
+	}
+
+	// Nothing was applied
+	if log.GetLevel() != log.DefaultLogLevel || len(log.PackageLevels()) != 0 {
+		t.Errorf("got level %s, packages %v", log.GetLevel(), log.PackageLevels())
+	}
+}
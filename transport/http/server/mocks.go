@@ -13,6 +13,7 @@ import (
 	mock "github.com/stretchr/testify/mock"
 	"github.com/valyala/fasthttp"
 	"go.microcore.dev/framework/telemetry"
+	"go.microcore.dev/framework/transport/http/server/openapi"
 )
 
 // NewMockManager creates a new instance of MockManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -272,6 +273,68 @@ func (_c *MockManager_EnableTLS_Call) RunAndReturn(run func(tls *TLS) Manager) *
 	return _c
 }
 
+// GetOpenAPI provides a mock function for the type MockManager
+func (_mock *MockManager) GetOpenAPI(opts ...openapi.Option) *openapi.Document {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(opts)
+	} else {
+		tmpRet = _mock.Called()
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenAPI")
+	}
+
+	var r0 *openapi.Document
+	if returnFunc, ok := ret.Get(0).(func(...openapi.Option) *openapi.Document); ok {
+		r0 = returnFunc(opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*openapi.Document)
+		}
+	}
+	return r0
+}
+
+// MockManager_GetOpenAPI_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOpenAPI'
+type MockManager_GetOpenAPI_Call struct {
+	*mock.Call
+}
+
+// GetOpenAPI is a helper method to define mock.On call
+//   - opts ...openapi.Option
+func (_e *MockManager_Expecter) GetOpenAPI(opts ...interface{}) *MockManager_GetOpenAPI_Call {
+	return &MockManager_GetOpenAPI_Call{Call: _e.mock.On("GetOpenAPI",
+		append([]interface{}{}, opts...)...)}
+}
+
+func (_c *MockManager_GetOpenAPI_Call) Run(run func(opts ...openapi.Option)) *MockManager_GetOpenAPI_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []openapi.Option
+		var variadicArgs []openapi.Option
+		if len(args) > 0 {
+			variadicArgs = args[0].([]openapi.Option)
+		}
+		arg0 = variadicArgs
+		run(
+			arg0...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockManager_GetOpenAPI_Call) Return(document *openapi.Document) *MockManager_GetOpenAPI_Call {
+	_c.Call.Return(document)
+	return _c
+}
+
+func (_c *MockManager_GetOpenAPI_Call) RunAndReturn(run func(opts ...openapi.Option) *openapi.Document) *MockManager_GetOpenAPI_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetShutdownHandler provides a mock function for the type MockManager
 func (_mock *MockManager) GetShutdownHandler() bool {
 	ret := _mock.Called()
@@ -708,6 +771,68 @@ func (_c *MockManager_Up_Call) RunAndReturn(run func()) *MockManager_Up_Call {
 	return _c
 }
 
+// UseCompression provides a mock function for the type MockManager
+func (_mock *MockManager) UseCompression(opts ...CompressionOption) Manager {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(opts)
+	} else {
+		tmpRet = _mock.Called()
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseCompression")
+	}
+
+	var r0 Manager
+	if returnFunc, ok := ret.Get(0).(func(...CompressionOption) Manager); ok {
+		r0 = returnFunc(opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Manager)
+		}
+	}
+	return r0
+}
+
+// MockManager_UseCompression_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseCompression'
+type MockManager_UseCompression_Call struct {
+	*mock.Call
+}
+
+// UseCompression is a helper method to define mock.On call
+//   - opts ...CompressionOption
+func (_e *MockManager_Expecter) UseCompression(opts ...interface{}) *MockManager_UseCompression_Call {
+	return &MockManager_UseCompression_Call{Call: _e.mock.On("UseCompression",
+		append([]interface{}{}, opts...)...)}
+}
+
+func (_c *MockManager_UseCompression_Call) Run(run func(opts ...CompressionOption)) *MockManager_UseCompression_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []CompressionOption
+		var variadicArgs []CompressionOption
+		if len(args) > 0 {
+			variadicArgs = args[0].([]CompressionOption)
+		}
+		arg0 = variadicArgs
+		run(
+			arg0...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockManager_UseCompression_Call) Return(manager Manager) *MockManager_UseCompression_Call {
+	_c.Call.Return(manager)
+	return _c
+}
+
+func (_c *MockManager_UseCompression_Call) RunAndReturn(run func(opts ...CompressionOption) Manager) *MockManager_UseCompression_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UseCors provides a mock function for the type MockManager
 func (_mock *MockManager) UseCors(opts ...CorsOption) Manager {
 	var tmpRet mock.Arguments
@@ -770,6 +895,114 @@ func (_c *MockManager_UseCors_Call) RunAndReturn(run func(opts ...CorsOption) Ma
 	return _c
 }
 
+// UseLogLevels provides a mock function for the type MockManager
+func (_mock *MockManager) UseLogLevels() Manager {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseLogLevels")
+	}
+
+	var r0 Manager
+	if returnFunc, ok := ret.Get(0).(func() Manager); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Manager)
+		}
+	}
+	return r0
+}
+
+// MockManager_UseLogLevels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseLogLevels'
+type MockManager_UseLogLevels_Call struct {
+	*mock.Call
+}
+
+// UseLogLevels is a helper method to define mock.On call
+func (_e *MockManager_Expecter) UseLogLevels() *MockManager_UseLogLevels_Call {
+	return &MockManager_UseLogLevels_Call{Call: _e.mock.On("UseLogLevels")}
+}
+
+func (_c *MockManager_UseLogLevels_Call) Run(run func()) *MockManager_UseLogLevels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockManager_UseLogLevels_Call) Return(manager Manager) *MockManager_UseLogLevels_Call {
+	_c.Call.Return(manager)
+	return _c
+}
+
+func (_c *MockManager_UseLogLevels_Call) RunAndReturn(run func() Manager) *MockManager_UseLogLevels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseOpenAPI provides a mock function for the type MockManager
+func (_mock *MockManager) UseOpenAPI(opts ...openapi.Option) Manager {
+	var tmpRet mock.Arguments
+	if len(opts) > 0 {
+		tmpRet = _mock.Called(opts)
+	} else {
+		tmpRet = _mock.Called()
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseOpenAPI")
+	}
+
+	var r0 Manager
+	if returnFunc, ok := ret.Get(0).(func(...openapi.Option) Manager); ok {
+		r0 = returnFunc(opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Manager)
+		}
+	}
+	return r0
+}
+
+// MockManager_UseOpenAPI_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseOpenAPI'
+type MockManager_UseOpenAPI_Call struct {
+	*mock.Call
+}
+
+// UseOpenAPI is a helper method to define mock.On call
+//   - opts ...openapi.Option
+func (_e *MockManager_Expecter) UseOpenAPI(opts ...interface{}) *MockManager_UseOpenAPI_Call {
+	return &MockManager_UseOpenAPI_Call{Call: _e.mock.On("UseOpenAPI",
+		append([]interface{}{}, opts...)...)}
+}
+
+func (_c *MockManager_UseOpenAPI_Call) Run(run func(opts ...openapi.Option)) *MockManager_UseOpenAPI_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 []openapi.Option
+		var variadicArgs []openapi.Option
+		if len(args) > 0 {
+			variadicArgs = args[0].([]openapi.Option)
+		}
+		arg0 = variadicArgs
+		run(
+			arg0...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockManager_UseOpenAPI_Call) Return(manager Manager) *MockManager_UseOpenAPI_Call {
+	_c.Call.Return(manager)
+	return _c
+}
+
+func (_c *MockManager_UseOpenAPI_Call) RunAndReturn(run func(opts ...openapi.Option) Manager) *MockManager_UseOpenAPI_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UseProfiling provides a mock function for the type MockManager
 func (_mock *MockManager) UseProfiling() Manager {
 	ret := _mock.Called()
@@ -0,0 +1,208 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/server/openapi"
+
+	fastHttpSwagger "github.com/swaggo/fasthttp-swagger"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// routeDoc describes a route in the OpenAPI document.
+	routeDoc struct {
+		summary     string
+		description string
+		operationID string
+		tags        []string
+		deprecated  bool
+		hidden      bool
+		request     reflect.Type
+		bind        reflect.Type
+		result      reflect.Type
+		status      http.StatusCode
+		responses   []routeResponse
+		errors      []error
+	}
+
+	routeResponse struct {
+		statusCode http.StatusCode
+		body       reflect.Type
+	}
+)
+
+// UseOpenAPI serves an OpenAPI 3.1 document of the registered routes at
+// DefaultOpenAPIPath and a Swagger UI for it at DefaultOpenAPIUIPath.
+//
+// The document is built on each request, so routes may be added before or
+// after UseOpenAPI is called. See GetOpenAPI.
+func (s *server) UseOpenAPI(opts ...openapi.Option) Manager {
+	s.router.Handle(http.MethodGet, DefaultOpenAPIPath, func(ctx *fasthttp.RequestCtx) {
+		c := &RequestContext{RequestCtx: ctx}
+		doc, err := json.Marshal(s.GetOpenAPI(opts...))
+		if err != nil {
+			c.WriteError(err)
+			return
+		}
+		c.SetContentType("application/json; charset=utf-8")
+		c.Write(doc)
+	})
+
+	ui := fastHttpSwagger.WrapHandler(fastHttpSwagger.URL(DefaultOpenAPIPath))
+	s.router.Handle(http.MethodGet, DefaultOpenAPIUIPath+"/{filepath:*}", func(ctx *fasthttp.RequestCtx) {
+		if ctx.UserValue("filepath") == "" {
+			ctx.Redirect(DefaultOpenAPIUIPath+"/index.html", fasthttp.StatusMovedPermanently)
+			return
+		}
+		ui(ctx)
+	})
+
+	return s
+}
+
+// GetOpenAPI builds an OpenAPI 3.1 document of the routes registered with
+// AddRoute and AddRouteGroup.
+//
+// Parameters come from the route paths and WithRouteBindHandler, request
+// bodies from WithRouteBodyParserHandler and WithRouteBindHandler, and
+// responses from WithRouteResponse,
+// WithRouteEmptyResponse, WithRouteTypedHandler and WithRouteErrors. Errors
+// are described as Problem if WithProblemDetails is set.
+func (s *server) GetOpenAPI(opts ...openapi.Option) *openapi.Document {
+	doc := openapi.New(opts...)
+	for _, route := range s.routes {
+		if route.doc.hidden {
+			continue
+		}
+		doc.AddOperation(route.method, route.path, route.doc.operation(doc, s.problemDetails))
+	}
+	return doc
+}
+
+func (d *routeDoc) operation(doc *openapi.Document, problemDetails bool) *openapi.Operation {
+	op := &openapi.Operation{
+		Tags:        d.tags,
+		Summary:     d.summary,
+		Description: d.description,
+		OperationID: d.operationID,
+		Deprecated:  d.deprecated,
+		Responses:   map[string]*openapi.Response{},
+	}
+
+	if d.request != nil {
+		op.RequestBody = doc.JsonRequestBody(d.request)
+	}
+	if d.bind != nil {
+		op.Parameters = doc.Parameters(d.bind)
+		if info := getBindInfo(d.bind); info.body {
+			op.RequestBody = doc.JsonRequestBody(d.bind)
+			op.RequestBody.Required = false
+		}
+	}
+
+	for _, r := range d.responses {
+		op.Responses[strconv.Itoa(int(r.statusCode))] = doc.JsonResponse(
+			fasthttp.StatusMessage(int(r.statusCode)),
+			r.body,
+		)
+	}
+	if len(op.Responses) == 0 {
+		status, result := d.status, d.result
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if result != nil && result.Kind() == reflect.Struct && result.NumField() == 0 {
+			result = nil
+		}
+		op.Responses[strconv.Itoa(int(status))] = doc.JsonResponse(
+			fasthttp.StatusMessage(int(status)),
+			result,
+		)
+	}
+
+	// Errors sharing a status code are described by one response
+	codes := map[http.StatusCode][]string{}
+	statusCodes := []http.StatusCode{}
+	for _, err := range d.errors {
+		statusCode, code := errorStatusCode(err)
+		if _, ok := codes[statusCode]; !ok {
+			statusCodes = append(statusCodes, statusCode)
+		}
+		if code != "" && !slices.Contains(codes[statusCode], code) {
+			codes[statusCode] = append(codes[statusCode], code)
+		}
+	}
+	contentType, errType := openapi.ContentTypeJson, reflect.TypeFor[ErrResponse]()
+	if problemDetails {
+		contentType, errType = ContentTypeProblemJson, reflect.TypeFor[Problem]()
+	}
+	for _, statusCode := range statusCodes {
+		description := fasthttp.StatusMessage(int(statusCode))
+		if len(codes[statusCode]) > 0 {
+			description += ": " + strings.Join(codes[statusCode], ", ")
+		}
+		op.Responses[strconv.Itoa(int(statusCode))] = doc.ContentResponse(
+			description,
+			contentType,
+			errType,
+		)
+	}
+
+	return op
+}
+
+// errorStatusCode returns the status code and error code of err, written by
+// RequestContext.WriteError and documented by the generated OpenAPI document.
+func errorStatusCode(err error) (http.StatusCode, string) {
+	var e transport.Error
+	if errors.As(err, &e) {
+		statusCode, ok := http.ErrStatusCodeMap[e.Base]
+		if !ok {
+			statusCode = http.ErrStatusCodeMap[defaultResponseErr]
+		}
+		return statusCode, e.GetCode()
+	}
+	if base, ok := baseError(err); ok {
+		return http.ErrStatusCodeMap[base], baseErrorCode(base)
+	}
+	return http.ErrStatusCodeMap[defaultResponseErr], defaultResponseCode
+}
+
+// routeGroupRoutes returns the routes of routeGroup and its nested groups
+// with their full paths, joined the same way the router joins group paths.
+func routeGroupRoutes(prefix string, routeGroup *routeGroup, tags []string) []rawRoute {
+	if prefix == "" || routeGroup.path != "/" {
+		prefix += routeGroup.path
+	}
+	if len(routeGroup.tags) > 0 {
+		tags = routeGroup.tags
+	}
+
+	routes := []rawRoute{}
+	for _, route := range routeGroup.rawRoutes {
+		if prefix != "/" {
+			route.path = prefix + route.path
+		}
+		if len(route.doc.tags) == 0 && len(tags) > 0 {
+			doc := *route.doc
+			doc.tags = tags
+			route.doc = &doc
+		}
+		routes = append(routes, route)
+	}
+
+	for _, g := range routeGroup.routeGroups {
+		routes = append(routes, routeGroupRoutes(prefix, g, tags)...)
+	}
+
+	return routes
+}
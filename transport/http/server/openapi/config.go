@@ -0,0 +1,17 @@
+package openapi // import "go.microcore.dev/framework/transport/http/server/openapi"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/transport/http/server/openapi"
+
+	// Version is the OpenAPI specification version of generated documents.
+	Version = "3.1.0"
+
+	DefaultTitle   = "API"
+	DefaultVersion = "1.0.0"
+
+	ContentTypeJson = "application/json"
+)
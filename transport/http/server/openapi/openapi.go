@@ -0,0 +1,431 @@
+package openapi // import "go.microcore.dev/framework/transport/http/server/openapi"
+
+/*
+Package openapi builds OpenAPI 3.1 documents from Go types.
+
+The HTTP server uses it to describe registered routes (see
+server.Manager.UseOpenAPI), but a Document can also be assembled by hand:
+
+	doc := openapi.New(openapi.WithTitle("users"))
+	doc.AddOperation("GET", "/users/{id}", &openapi.Operation{
+	    Summary: "Get user",
+	    Responses: map[string]*openapi.Response{
+	        "200": doc.JsonResponse("OK", reflect.TypeFor[User]()),
+	    },
+	})
+
+Request and response schemas are derived from struct fields and their json
+tags. Named struct types are registered once under components/schemas and
+referenced from operations.
+*/
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	_ "go.microcore.dev/framework"
+)
+
+type (
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Servers    []Server             `json:"servers,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components,omitzero"`
+
+		types map[reflect.Type]string
+	}
+
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	Server struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem maps lower-case HTTP methods to operations.
+	PathItem map[string]*Operation
+
+	Operation struct {
+		Tags        []string             `json:"tags,omitempty"`
+		Summary     string               `json:"summary,omitempty"`
+		Description string               `json:"description,omitempty"`
+		OperationID string               `json:"operationId,omitempty"`
+		Parameters  []*Parameter         `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses"`
+		Deprecated  bool                 `json:"deprecated,omitempty"`
+	}
+
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema,omitempty"`
+	}
+
+	RequestBody struct {
+		Description string                `json:"description,omitempty"`
+		Required    bool                  `json:"required,omitempty"`
+		Content     map[string]*MediaType `json:"content"`
+	}
+
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	MediaType struct {
+		Schema *Schema `json:"schema,omitempty"`
+	}
+
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas,omitempty"`
+	}
+
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		Enum                 []any              `json:"enum,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		Default              any                `json:"default,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	}
+)
+
+var (
+	// Struct tags of fields bound from request parameters
+	parameterTags = []string{"path", "query", "header"}
+
+	pathParamRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+	schemaNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func New(opts ...Option) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   DefaultTitle,
+			Version: DefaultVersion,
+		},
+		Paths: map[string]*PathItem{},
+		types: map[reflect.Type]string{},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// AddOperation adds op to the document under method and path.
+//
+// path uses the router syntax: parameters such as {id}, {id?}, {id:[0-9]+}
+// and {filepath:*} are rewritten to {id} and declared as path parameters
+// of op, unless op already declares them, with regular expressions kept as
+// the schema pattern.
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	path = pathParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		name, pattern, _ := strings.Cut(s[1:len(s)-1], ":")
+		name = strings.TrimSuffix(name, "?")
+
+		var param *Parameter
+		for _, p := range op.Parameters {
+			if p.In == "path" && p.Name == name {
+				param = p
+			}
+		}
+		if param == nil {
+			param = &Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		if pattern != "" && pattern != "*" && param.Schema.Type == "string" {
+			param.Schema.Pattern = "^" + pattern + "$"
+		}
+
+		return "{" + name + "}"
+	})
+
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// JsonRequestBody returns a required JSON request body with the schema of t.
+func (d *Document) JsonRequestBody(t reflect.Type) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			ContentTypeJson: {Schema: d.Schema(t)},
+		},
+	}
+}
+
+// JsonResponse returns a response with description and, if t is not nil,
+// a JSON body with the schema of t.
+func (d *Document) JsonResponse(description string, t reflect.Type) *Response {
+	return d.ContentResponse(description, ContentTypeJson, t)
+}
+
+// ContentResponse returns a response with description and, if t is not
+// nil, a body of contentType with the schema of t.
+func (d *Document) ContentResponse(description, contentType string, t reflect.Type) *Response {
+	r := &Response{Description: description}
+	if t != nil {
+		r.Content = map[string]*MediaType{
+			contentType: {Schema: d.Schema(t)},
+		}
+	}
+	return r
+}
+
+// Parameters returns the parameters of the struct type t declared with
+// `path:"name"`, `query:"name"` and `header:"Name"` field tags. The
+// `default:"value"` tag sets the schema default and `validate:"required"`
+// marks the parameter required.
+func (d *Document) Parameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	params := []*Parameter{}
+	if t.Kind() != reflect.Struct {
+		return params
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && !isParameter(f) && f.Tag.Get("json") == "" {
+			params = append(params, d.Parameters(f.Type)...)
+			continue
+		}
+		for _, in := range parameterTags {
+			name := f.Tag.Get(in)
+			if name == "" {
+				continue
+			}
+			schema := d.parameterSchema(f.Type)
+			if def := f.Tag.Get("default"); def != "" {
+				schema.Default = def
+				// Typed default for numbers and booleans
+				var v any
+				if schema.Type != "string" && json.Unmarshal([]byte(def), &v) == nil {
+					schema.Default = v
+				}
+			}
+			params = append(params, &Parameter{
+				Name:     name,
+				In:       in,
+				Required: in == "path" || hasTagOption(f.Tag.Get("validate"), "required"),
+				Schema:   schema,
+			})
+			break
+		}
+	}
+
+	return params
+}
+
+// parameterSchema returns the schema of a request parameter of type t.
+func (d *Document) parameterSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		return &Schema{Type: "array", Items: d.parameterSchema(t.Elem())}
+	}
+	return d.Schema(t)
+}
+
+// Schema returns the schema of t. Named struct types are registered under
+// components/schemas and a reference to them is returned.
+func (d *Document) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// The encoding is unknown
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: new(float64)}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Minimum: new(float64)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: d.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + d.register(t)}
+	default:
+		// Interfaces and anything without a JSON representation
+		return &Schema{}
+	}
+}
+
+// register adds the schema of the named struct type t to the components
+// and returns its name.
+func (d *Document) register(t reflect.Type) string {
+	if name, ok := d.types[t]; ok {
+		return name
+	}
+
+	name := schemaNameRegex.ReplaceAllString(t.Name(), "_")
+	if _, ok := d.Components.Schemas[name]; ok {
+		// Same type name in another package
+		name = schemaNameRegex.ReplaceAllString(path.Base(t.PkgPath())+"."+t.Name(), "_")
+	}
+
+	if d.Components.Schemas == nil {
+		d.Components.Schemas = map[string]*Schema{}
+	}
+
+	// Reserve the name first, so recursive types refer to themselves
+	d.types[t] = name
+	d.Components.Schemas[name] = &Schema{}
+	*d.Components.Schemas[name] = *d.structSchema(t)
+
+	return name
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+	d.addFields(s, t)
+	return s
+}
+
+// addFields adds the fields of the struct type t to s the way encoding/json
+// marshals them, inlining embedded structs without a json name. Fields
+// bound from request parameters are skipped, see Parameters.
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if isParameter(f) {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			d.addFields(s, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		var schema *Schema
+		if hasTagOption(opts, "string") {
+			schema = &Schema{Type: "string"}
+		} else {
+			schema = d.Schema(f.Type)
+		}
+		s.Properties[name] = schema
+
+		if !hasTagOption(opts, "omitempty") && !hasTagOption(opts, "omitzero") ||
+			hasTagOption(f.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func isParameter(f reflect.StructField) bool {
+	for _, in := range parameterTags {
+		if f.Tag.Get(in) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created,omitempty"`
+	Children []*node   `json:"children,omitempty"`
+	Data     []byte    `json:"data,omitempty"`
+	Skip     string    `json:"-"`
+	private  string
+}
+
+func TestDocument(t *testing.T) {
+	d := New(WithTitle("test"))
+
+	d.AddOperation("GET", "/nodes/{id:[0-9]+}/{filepath:*}", &Operation{
+		Responses: map[string]*Response{
+			"200": d.JsonResponse("OK", reflect.TypeFor[node]()),
+		},
+	})
+
+	item, ok := d.Paths["/nodes/{id}/{filepath}"]
+	if !ok {
+		t.Fatalf("path not rewritten: %v", d.Paths)
+	}
+	op := (*item)["get"]
+	if len(op.Parameters) != 2 || op.Parameters[0].Name != "id" || op.Parameters[0].Schema.Pattern != "^[0-9]+$" ||
+		op.Parameters[1].Name != "filepath" || op.Parameters[1].Schema.Pattern != "" {
+		t.Fatalf("unexpected parameters: %+v", op.Parameters)
+	}
+	if ref := op.Responses["200"].Content[ContentTypeJson].Schema.Ref; ref != "#/components/schemas/node" {
+		t.Fatalf("unexpected response schema ref %q", ref)
+	}
+
+	s := d.Components.Schemas["node"]
+	if s == nil || s.Type != "object" {
+		t.Fatalf("schema not registered: %v", d.Components.Schemas)
+	}
+	if len(s.Properties) != 4 || !slices.Equal(s.Required, []string{"name"}) {
+		t.Fatalf("unexpected properties %v, required %v", s.Properties, s.Required)
+	}
+	if s.Properties["created"].Format != "date-time" || s.Properties["data"].ContentEncoding != "base64" {
+		t.Fatalf("unexpected property schemas: %+v", s.Properties)
+	}
+	if s.Properties["children"].Items.Ref != "#/components/schemas/node" {
+		t.Fatalf("expected recursive reference, got %+v", s.Properties["children"].Items)
+	}
+}
@@ -0,0 +1,33 @@
+package openapi // import "go.microcore.dev/framework/transport/http/server/openapi"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+type Option func(*Document)
+
+func WithTitle(title string) Option {
+	return func(d *Document) {
+		d.Info.Title = title
+	}
+}
+
+func WithDescription(description string) Option {
+	return func(d *Document) {
+		d.Info.Description = description
+	}
+}
+
+func WithVersion(version string) Option {
+	return func(d *Document) {
+		d.Info.Version = version
+	}
+}
+
+func WithServers(urls ...string) Option {
+	return func(d *Document) {
+		for _, url := range urls {
+			d.Servers = append(d.Servers, Server{URL: url})
+		}
+	}
+}
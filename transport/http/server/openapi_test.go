@@ -0,0 +1,31 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+)
+
+func TestErrorStatusCode(t *testing.T) {
+	defaultStatus := http.ErrStatusCodeMap[defaultResponseErr]
+
+	for _, tt := range []struct {
+		err    error
+		status http.StatusCode
+		code   string
+	}{
+		{transport.NewError(transport.ErrConflict, "user exists", "USER_EXISTS"), http.StatusConflict, "USER_EXISTS"},
+		{fmt.Errorf("user: %w", transport.ErrNotFound), http.StatusNotFound, "NOT_FOUND"},
+		{transport.NewError(errors.New("unmapped"), "unmapped", "UNMAPPED"), defaultStatus, "UNMAPPED"},
+		{errors.New("internal"), defaultStatus, defaultResponseCode},
+		{transport.NewError(transport.NewErrorWithDetails(transport.ErrBadRequest, "invalid", "INVALID", []transport.FieldError{{Field: "name"}}), "wrapped", "WRAPPED"), defaultStatus, "WRAPPED"},
+	} {
+		status, code := errorStatusCode(tt.err)
+		if status != tt.status || code != tt.code {
+			t.Errorf("%v: got %d %s, want %d %s", tt.err, status, code, tt.status, tt.code)
+		}
+	}
+}
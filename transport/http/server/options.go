@@ -2,8 +2,11 @@ package server // import "go.microcore.dev/framework/transport/http/server"
 
 import (
 	"context"
-	"encoding/json"
+	"log/slog"
 	"net"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	fasthttpRouter "github.com/fasthttp/router"
@@ -12,9 +15,12 @@ import (
 	_ "go.microcore.dev/framework"
 	"go.microcore.dev/framework/telemetry"
 	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
 	"go.microcore.dev/framework/transport/http/server/core"
 	"go.microcore.dev/framework/transport/http/server/listener"
 	"go.microcore.dev/framework/transport/http/server/router"
+	"go.microcore.dev/framework/validate"
 )
 
 type Option func(*server) error
@@ -93,6 +99,41 @@ func WithTLS(tls *TLS) Option {
 	}
 }
 
+// WithErrorRenderer sets the renderer of the error responses written by
+// RequestContext.WriteError. The default is JsonErrorRenderer.
+func WithErrorRenderer(renderer ErrorRenderer) Option {
+	return func(s *server) error {
+		s.errorRenderer = renderer
+		s.problemDetails = false
+		return nil
+	}
+}
+
+// WithProblemDetails writes error responses as RFC 9457
+// application/problem+json with ProblemErrorRenderer(typeBaseURI), and
+// documents them as such in the OpenAPI document.
+func WithProblemDetails(typeBaseURI string) Option {
+	return func(s *server) error {
+		s.errorRenderer = ProblemErrorRenderer(typeBaseURI)
+		s.problemDetails = true
+		return nil
+	}
+}
+
+// WithCodecs registers codecs used to decode request bodies and encode
+// responses, replacing the built-in codecs of the same media types. See
+// codec.Registry.
+//
+// Example, to use a faster JSON implementation:
+//
+//	server.WithCodecs(codec.NewJson(sonic.Marshal, sonic.Unmarshal))
+func WithCodecs(codecs ...codec.Codec) Option {
+	return func(s *server) error {
+		s.codecs.Register(codecs...)
+		return nil
+	}
+}
+
 type RouteOption func(*route)
 
 func WithRouteMethod(method string) RouteOption {
@@ -115,30 +156,52 @@ func WithRouteHandler(handler func(context.Context, *RequestContext)) RouteOptio
 	}
 }
 
+// WithRouteBodyParserHandler decodes the request body into T with
+// RequestContext.ReadBody, validates it and calls handler. Bodies are
+// validated with their Validate() error method if any, and with their
+// struct tags if WithRouteBodyValidation is used. The body is
+// decoded with the codec of its Content-Type, JSON if absent, so bodies
+// sent with an unsupported type such as application/x-www-form-urlencoded
+// (the default of curl -d) are answered with 415 Unsupported Media Type.
 func WithRouteBodyParserHandler[T any](handler func(context.Context, *RequestContext, *T)) RouteOption {
 	return func(r *route) {
 		r.handler = func(c *RequestContext) {
 			var body T
-			if err := json.Unmarshal(c.Request.Body(), &body); err == nil {
-				if v, ok := any(&body).(interface{ Validate() error }); ok {
-					if err := v.Validate(); err != nil {
-						c.WriteError(err)
-						return
-					}
-				}
-			} else {
-				c.WriteError(
-					transport.NewError(
-						transport.ErrUnsupportedMediaType,
-						"invalid json body",
-						"INVALID_JSON_BODY",
-					),
-				)
+			if err := c.ReadBody(&body); err != nil {
+				c.WriteError(err)
 				return
 			}
+			if r.validate && reflect.TypeFor[T]().Kind() == reflect.Struct {
+				if err := validate.Struct(&body); err != nil {
+					c.WriteError(err)
+					return
+				}
+			}
+			if v, ok := any(&body).(interface{ Validate() error }); ok {
+				if err := v.Validate(); err != nil {
+					c.WriteError(err)
+					return
+				}
+			}
 
 			handler(extractRequestContext(c.RequestCtx), c, &body)
 		}
+		r.doc.request = reflect.TypeFor[T]()
+		r.doc.errors = append(r.doc.errors, transport.ErrUnsupportedMediaType)
+	}
+}
+
+// WithRouteBodyValidation validates the bodies decoded by
+// WithRouteBodyParserHandler with validate.Struct, answering invalid bodies
+// with 400 Bad Request. Tags of other validation libraries using the
+// validate key are not supported and fail every request.
+func WithRouteBodyValidation() RouteOption {
+	return func(r *route) {
+		r.validate = true
+		r.doc.errors = append(
+			r.doc.errors,
+			transport.NewError(transport.ErrBadRequest, "validation failed", validate.Code),
+		)
 	}
 }
 
@@ -148,6 +211,110 @@ func WithRouteMiddlewares(middlewares ...MiddlewareHandler) RouteOption {
 	}
 }
 
+// WithRouteSuccessStatus sets the status code WithRouteTypedHandler writes
+// responses with.
+func WithRouteSuccessStatus(statusCode http.StatusCode) RouteOption {
+	return func(r *route) {
+		r.status = statusCode
+	}
+}
+
+// WithRouteRoles requires the token verified by AuthMiddleware to grant one
+// of roles, see auth.Claims.Roles. Requests without token are answered with
+// transport.ErrUnauthorized and others with transport.ErrForbidden.
+func WithRouteRoles(roles ...string) RouteOption {
+	return func(r *route) {
+		r.roles = roles
+		r.doc.errors = append(r.doc.errors, routeAuthErrors("INSUFFICIENT_ROLE")...)
+	}
+}
+
+// WithRouteScopes requires the token verified by AuthMiddleware to grant all
+// scopes, see auth.Claims.Scopes. Requests without token are answered with
+// transport.ErrUnauthorized and others with transport.ErrForbidden.
+func WithRouteScopes(scopes ...string) RouteOption {
+	return func(r *route) {
+		r.scopes = scopes
+		r.doc.errors = append(r.doc.errors, routeAuthErrors("INSUFFICIENT_SCOPE")...)
+	}
+}
+
+// WithRouteSummary sets the short summary of the route in the OpenAPI
+// document.
+func WithRouteSummary(summary string) RouteOption {
+	return func(r *route) {
+		r.doc.summary = summary
+	}
+}
+
+func WithRouteDescription(description string) RouteOption {
+	return func(r *route) {
+		r.doc.description = description
+	}
+}
+
+func WithRouteOperationID(id string) RouteOption {
+	return func(r *route) {
+		r.doc.operationID = id
+	}
+}
+
+func WithRouteTags(tags ...string) RouteOption {
+	return func(r *route) {
+		r.doc.tags = tags
+	}
+}
+
+func WithRouteDeprecated() RouteOption {
+	return func(r *route) {
+		r.doc.deprecated = true
+	}
+}
+
+// WithRouteResponse declares a JSON response body of type T returned with
+// statusCode in the OpenAPI document.
+func WithRouteResponse[T any](statusCode http.StatusCode) RouteOption {
+	return func(r *route) {
+		r.doc.responses = append(r.doc.responses, routeResponse{
+			statusCode: statusCode,
+			body:       reflect.TypeFor[T](),
+		})
+	}
+}
+
+// WithRouteEmptyResponse declares a response without a body returned with
+// statusCode in the OpenAPI document.
+func WithRouteEmptyResponse(statusCode http.StatusCode) RouteOption {
+	return func(r *route) {
+		r.doc.responses = append(r.doc.responses, routeResponse{
+			statusCode: statusCode,
+		})
+	}
+}
+
+// WithRouteErrors declares the errors the route may write with
+// RequestContext.WriteError. In the OpenAPI document they are grouped by the
+// status code of http.ErrStatusCodeMap and described by their codes.
+//
+// Example:
+//
+//	server.WithRouteErrors(
+//	    transport.ErrNotFound,
+//	    transport.NewError(transport.ErrConflict, "user exists", "USER_EXISTS"),
+//	)
+func WithRouteErrors(errs ...error) RouteOption {
+	return func(r *route) {
+		r.doc.errors = append(r.doc.errors, errs...)
+	}
+}
+
+// WithoutRouteDoc hides the route from the OpenAPI document.
+func WithoutRouteDoc() RouteOption {
+	return func(r *route) {
+		r.doc.hidden = true
+	}
+}
+
 type RouteGroupOption func(*routeGroup)
 
 func WithRouteGroupPath(path string) RouteGroupOption {
@@ -162,6 +329,24 @@ func WithRouteGroupMiddlewares(middlewares ...MiddlewareHandler) RouteGroupOptio
 	}
 }
 
+// WithRouteGroupTags sets the OpenAPI tags of the routes in the group and
+// its nested groups that do not set their own.
+func WithRouteGroupTags(tags ...string) RouteGroupOption {
+	return func(r *routeGroup) {
+		r.tags = tags
+	}
+}
+
+// WithRouteGroupCors handles Cross-Origin Resource Sharing for the routes of
+// the group and its nested groups with CorsMiddleware, and answers their
+// preflight requests. See UseCors for all routes, which does not apply to
+// the routes of the group.
+func WithRouteGroupCors(opts ...CorsOption) RouteGroupOption {
+	return func(r *routeGroup) {
+		r.cors = newCors(opts...)
+	}
+}
+
 func WithRouteGroupRoute(opts ...RouteOption) RouteGroupOption {
 	return func(r *routeGroup) {
 		r.rawRoutes = append(r.rawRoutes, *newRawRoute(opts...))
@@ -176,20 +361,161 @@ func WithRouteGroup(opts ...RouteGroupOption) RouteGroupOption {
 
 type CorsOption func(*cors)
 
+// WithCorsOrigin sets the allowed origins as a comma-separated list, see
+// WithCorsOrigins.
 func WithCorsOrigin(origin string) CorsOption {
 	return func(c *cors) {
-		c.origin = origin
+		c.origins = nil
+		for o := range strings.SplitSeq(origin, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				c.origins = append(c.origins, o)
+			}
+		}
 	}
 }
 
+// WithCorsOrigins sets the allowed origins. * allows any origin, and a *
+// within an origin matches one or more characters, e.g.
+// https://*.example.com. Defaults to DefaultCorsOrigin.
+func WithCorsOrigins(origins ...string) CorsOption {
+	return func(c *cors) {
+		c.origins = origins
+	}
+}
+
+// WithCorsOriginPatterns allows the origins matching one of patterns in
+// addition to the origins of WithCorsOrigins. Origins are lower-cased
+// before matching.
+func WithCorsOriginPatterns(patterns ...*regexp.Regexp) CorsOption {
+	return func(c *cors) {
+		c.originPatterns = append(c.originPatterns, patterns...)
+	}
+}
+
+// WithCorsMethods sets the Access-Control-Allow-Methods header of preflight
+// responses. Defaults to DefaultCorsMethods.
 func WithCorsMethods(methods string) CorsOption {
 	return func(c *cors) {
 		c.methods = methods
 	}
 }
 
+// WithCorsHeaders sets the Access-Control-Allow-Headers header of preflight
+// responses. Defaults to DefaultCorsHeaders.
 func WithCorsHeaders(headers string) CorsOption {
 	return func(c *cors) {
 		c.headers = headers
 	}
 }
+
+// WithCorsExposedHeaders sets the Access-Control-Expose-Headers header,
+// e.g. "X-Trace-Id, RateLimit-Remaining".
+func WithCorsExposedHeaders(headers string) CorsOption {
+	return func(c *cors) {
+		c.exposedHeaders = headers
+	}
+}
+
+// WithCorsCredentials allows requests with credentials such as cookies from
+// the origins of WithCorsOrigins and WithCorsOriginPatterns, which must not
+// include *: credentials are ignored if all origins are allowed. * methods
+// and headers allow the requested ones.
+func WithCorsCredentials() CorsOption {
+	return func(c *cors) {
+		c.credentials = true
+	}
+}
+
+// WithCorsMaxAge sets how long browsers may cache preflight responses.
+func WithCorsMaxAge(maxAge time.Duration) CorsOption {
+	return func(c *cors) {
+		c.maxAge = maxAge
+	}
+}
+
+type CompressionOption func(*compression)
+
+// WithCompressionAlgorithms sets the response compression algorithms among
+// CompressionGzip, CompressionBrotli and CompressionZstd, in order of
+// preference.
+func WithCompressionAlgorithms(algorithms ...string) CompressionOption {
+	return func(c *compression) {
+		c.algorithms = algorithms
+	}
+}
+
+// WithCompressionLevel sets the compression level of all algorithms.
+// Levels other than CompressionLevelDefault, CompressionLevelBestSpeed and
+// CompressionLevelBestCompression are ignored.
+func WithCompressionLevel(level CompressionLevel) CompressionOption {
+	return func(c *compression) {
+		if _, ok := gzipLevels[level]; !ok {
+			logger.Warn(
+				"invalid compression level, ignoring",
+				slog.Int("level", int(level)),
+			)
+			return
+		}
+		c.level = level
+	}
+}
+
+// WithCompressionMinSize sets the minimum body size in bytes of compressed
+// responses. Defaults to DefaultCompressionMinSize.
+func WithCompressionMinSize(size int) CompressionOption {
+	return func(c *compression) {
+		c.minSize = size
+	}
+}
+
+// WithCompressionContentTypes sets the media types of compressed responses.
+// A type/* entry allows all subtypes of type.
+func WithCompressionContentTypes(contentTypes ...string) CompressionOption {
+	return func(c *compression) {
+		c.contentTypes = contentTypes
+	}
+}
+
+// WithCompressionMaxRequestSize sets the maximum decompressed size in bytes
+// of gzip request bodies. Defaults to DefaultCompressionMaxRequestSize.
+func WithCompressionMaxRequestSize(size int) CompressionOption {
+	return func(c *compression) {
+		c.maxRequestSize = size
+	}
+}
+
+// WithoutCompressionRequestDecompression leaves gzip request bodies to the
+// handlers.
+func WithoutCompressionRequestDecompression() CompressionOption {
+	return func(c *compression) {
+		c.decompress = false
+	}
+}
+
+type RateLimitOption func(*rateLimit)
+
+// WithRateLimitKey sets the key requests are limited by, e.g.
+// RateLimitByBearerToken or a custom function.
+func WithRateLimitKey(key RateLimitKeyFunc) RateLimitOption {
+	return func(r *rateLimit) {
+		r.key = key
+	}
+}
+
+// WithoutRateLimitHeaders omits the RateLimit-* headers. Retry-After is
+// still set on limited requests.
+func WithoutRateLimitHeaders() RateLimitOption {
+	return func(r *rateLimit) {
+		r.headers = false
+	}
+}
+
+type AuthOption func(*authentication)
+
+// WithAuthOptional lets requests without bearer token through without
+// claims. Requests with an invalid token are still rejected.
+func WithAuthOptional() AuthOption {
+	return func(a *authentication) {
+		a.optional = true
+	}
+}
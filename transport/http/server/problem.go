@@ -0,0 +1,84 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"encoding/json"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// ErrorRenderer writes the error response resp with statusCode.
+	// RequestContext.WriteError resolves both from the error and calls the
+	// renderer configured with WithErrorRenderer, or JsonErrorRenderer.
+	ErrorRenderer func(c *RequestContext, statusCode http.StatusCode, resp ErrResponse)
+
+	// Problem is an RFC 9457 problem details object, written by
+	// ProblemErrorRenderer.
+	Problem struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+
+		// Extension members
+		Code    string                 `json:"code,omitempty"`
+		TraceID string                 `json:"trace_id,omitempty"`
+		Errors  []transport.FieldError `json:"errors,omitempty"`
+	}
+)
+
+// JsonErrorRenderer writes resp as JSON. It is the default ErrorRenderer.
+func JsonErrorRenderer(c *RequestContext, statusCode http.StatusCode, resp ErrResponse) {
+	c.WriteJsonWithStatusCode(statusCode, resp)
+}
+
+// ProblemErrorRenderer returns an ErrorRenderer writing RFC 9457
+// application/problem+json responses.
+//
+// The problem type is typeBaseURI followed by the error code in lower
+// kebab case (e.g. https://example.com/problems/user-exists for
+// USER_EXISTS), or DefaultProblemType if typeBaseURI is empty. The request
+// path is the instance, and the error code, trace ID and validation details
+// are added as the code, trace_id and errors extension members.
+func ProblemErrorRenderer(typeBaseURI string) ErrorRenderer {
+	return func(c *RequestContext, statusCode http.StatusCode, resp ErrResponse) {
+		problem := Problem{
+			Type:     DefaultProblemType,
+			Title:    fasthttp.StatusMessage(int(statusCode)),
+			Status:   int(statusCode),
+			Detail:   resp.Message,
+			Instance: string(c.Path()),
+			Code:     resp.Code,
+			Errors:   resp.Details,
+		}
+
+		if typeBaseURI != "" && resp.Code != "" {
+			problem.Type = strings.TrimSuffix(typeBaseURI, "/") + "/" +
+				strings.ToLower(strings.ReplaceAll(resp.Code, "_", "-"))
+		}
+
+		if span := trace.SpanContextFromContext(c.GetContext()); span.HasTraceID() {
+			problem.TraceID = span.TraceID().String()
+		}
+
+		c.SetStatusCode(int(statusCode))
+		c.SetContentType(ContentTypeProblemJson)
+		json.NewEncoder(c).Encode(problem)
+	}
+}
+
+// getErrorRenderer returns the ErrorRenderer of the server handling the
+// request.
+func getErrorRenderer(c *fasthttp.RequestCtx) ErrorRenderer {
+	if r, ok := c.UserValue(errorRendererKey{}).(ErrorRenderer); ok {
+		return r
+	}
+	return JsonErrorRenderer
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	fasthttpRouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func writeError(renderer ErrorRenderer, err error) *fasthttp.Response {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/users/1")
+	if renderer != nil {
+		ctx.SetUserValue(errorRendererKey{}, renderer)
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx.SetUserValue("ctx", trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	})))
+	(&RequestContext{RequestCtx: &ctx}).WriteError(err)
+	resp := &fasthttp.Response{}
+	ctx.Response.CopyTo(resp)
+	return resp
+}
+
+func TestJsonErrorRenderer(t *testing.T) {
+	resp := writeError(nil, fmt.Errorf("user 1: %w", transport.ErrNotFound))
+	if resp.StatusCode() != 404 {
+		t.Errorf("status: got %d", resp.StatusCode())
+	}
+	var e ErrResponse
+	if err := json.Unmarshal(resp.Body(), &e); err != nil || e.Code != "NOT_FOUND" || e.Message != "not found" {
+		t.Errorf("body: got %s (%v)", resp.Body(), err)
+	}
+
+	// Wrapped messages are not disclosed
+	resp = writeError(nil, fmt.Errorf("query users: %w: %v", transport.ErrInternalServerError, errors.New("password authentication failed")))
+	if resp.StatusCode() != 500 {
+		t.Errorf("wrapped 5xx status: got %d", resp.StatusCode())
+	}
+	if err := json.Unmarshal(resp.Body(), &e); err != nil || e.Code != "INTERNAL_SERVER_ERROR" || e.Message != transport.ErrInternalServerError.Error() {
+		t.Errorf("wrapped 5xx body: got %s (%v)", resp.Body(), err)
+	}
+
+	// Unknown errors are not disclosed
+	resp = writeError(nil, errors.New("connection refused"))
+	if resp.StatusCode() != 503 {
+		t.Errorf("unknown error status: got %d", resp.StatusCode())
+	}
+	if err := json.Unmarshal(resp.Body(), &e); err != nil || e.Code != defaultResponseCode {
+		t.Errorf("unknown error body: got %s (%v)", resp.Body(), err)
+	}
+}
+
+func TestProblemErrorRenderer(t *testing.T) {
+	err := transport.NewErrorWithDetails(
+		transport.ErrBadRequest,
+		"invalid request",
+		"USER_EXISTS",
+		[]transport.FieldError{{Field: "email", In: BindInBody, Rule: "unique"}},
+	)
+
+	resp := writeError(ProblemErrorRenderer("https://example.com/problems/"), err)
+	if resp.StatusCode() != 400 || string(resp.Header.ContentType()) != ContentTypeProblemJson {
+		t.Errorf("got %d %s", resp.StatusCode(), resp.Header.ContentType())
+	}
+	var p Problem
+	if err := json.Unmarshal(resp.Body(), &p); err != nil {
+		t.Fatalf("invalid problem %s: %v", resp.Body(), err)
+	}
+	want := Problem{
+		Type:     "https://example.com/problems/user-exists",
+		Title:    "Bad Request",
+		Status:   400,
+		Detail:   err.Error(),
+		Instance: "/users/1",
+		Code:     "USER_EXISTS",
+		TraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "email" {
+		t.Errorf("errors: got %+v", p.Errors)
+	}
+	p.Errors = nil
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("problem: got %+v, want %+v", p, want)
+	}
+
+	// Without base URI, the type is about:blank
+	resp = writeError(ProblemErrorRenderer(""), transport.ErrConflict)
+	if err := json.Unmarshal(resp.Body(), &p); err != nil || p.Type != DefaultProblemType || p.Status != 409 {
+		t.Errorf("default type: got %s (%v)", resp.Body(), err)
+	}
+
+	// Bases missing from http.ErrStatusCodeMap get the default status
+	resp = writeError(ProblemErrorRenderer(""), transport.NewError(errors.New("unmapped"), "unmapped", "UNMAPPED"))
+	status := http.ErrStatusCodeMap[defaultResponseErr]
+	if resp.StatusCode() != int(status) {
+		t.Errorf("unmapped status: got %d, want %d", resp.StatusCode(), status)
+	}
+	p = Problem{}
+	if err := json.Unmarshal(resp.Body(), &p); err != nil || p.Status != int(status) || p.Title == "" || p.Code != "UNMAPPED" {
+		t.Errorf("unmapped problem: got %s (%v)", resp.Body(), err)
+	}
+}
+
+func TestServerSettingsPathParams(t *testing.T) {
+	codecs := codec.NewRegistry()
+	router := fasthttpRouter.New()
+	applyRoute(router, &rawRoute{
+		method: "GET",
+		path:   "/x/{codecs}/{errorRenderer}",
+		handler: func(c *RequestContext) {
+			if c.codecs() != codecs {
+				t.Error("codecs replaced by path parameter")
+			}
+			c.WriteError(transport.ErrNotFound)
+		},
+	})
+
+	// Set like the server does before routing
+	h := func(ctx *fasthttp.RequestCtx) {
+		ctx.SetUserValue(codecsKey{}, codecs)
+		ctx.SetUserValue(errorRendererKey{}, ProblemErrorRenderer(""))
+		router.Handler(ctx)
+	}
+
+	resp := request(h, "GET", "/x/a/b", nil)
+	if got := string(resp.Header.ContentType()); got != "application/problem+json" {
+		t.Errorf("got content type %q, body %s", got, resp.Body())
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"go.microcore.dev/framework/telemetry/telemetrytest"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestPropagation(t *testing.T) {
+	telemetrytest.AssertPropagation(t, func(inject func(propagation.TextMapCarrier)) propagation.TextMapCarrier {
+		var out fasthttp.RequestCtx
+		out.Request.SetRequestURI("http://example.com/x")
+		inject(fasthttpRequestCtxHeaderCarrier{&out})
+
+		var wire bytes.Buffer
+		if _, err := out.Request.WriteTo(&wire); err != nil {
+			t.Fatal(err)
+		}
+		var in fasthttp.RequestCtx
+		if err := in.Request.Read(bufio.NewReader(&wire)); err != nil {
+			t.Fatal(err)
+		}
+		return fasthttpRequestCtxHeaderCarrier{&in}
+	})
+}
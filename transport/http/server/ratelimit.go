@@ -0,0 +1,213 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
+	"go.microcore.dev/framework/ratelimit"
+	"go.microcore.dev/framework/transport"
+)
+
+type (
+	// RateLimitKeyFunc returns the key requests are limited by. Requests
+	// with an empty key are not limited.
+	RateLimitKeyFunc func(c *RequestContext) string
+
+	rateLimit struct {
+		key     RateLimitKeyFunc
+		headers bool
+	}
+)
+
+// RateLimitByIp limits requests by client IP address. It is the default
+// key, without trusted proxies.
+//
+// The client IP address is the remote address of the connection. If it is
+// one of trustedProxies, IP addresses or CIDR prefixes such as
+// "10.0.0.0/8", it is the last X-Forwarded-For address that is not a trusted
+// proxy, or the X-Real-IP header. Forwarding headers of other clients are
+// ignored, since they can be forged.
+func RateLimitByIp(trustedProxies ...string) RateLimitKeyFunc {
+	trusted := parseTrustedProxies(trustedProxies)
+	return func(c *RequestContext) string {
+		return "ip:" + clientIp(c, trusted)
+	}
+}
+
+// RateLimitByBearerToken limits requests by bearer token, and requests
+// without one by client IP address, see RateLimitByIp. Tokens are hashed,
+// so they are not stored in the limiter store.
+func RateLimitByBearerToken(trustedProxies ...string) RateLimitKeyFunc {
+	trusted := parseTrustedProxies(trustedProxies)
+	return func(c *RequestContext) string {
+		token, err := c.GetBearerToken()
+		if err != nil || token == "" {
+			return "ip:" + clientIp(c, trusted)
+		}
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// RateLimitByHeader limits requests by the value of the header name.
+// Requests without it are not limited.
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(c *RequestContext) string {
+		value := c.GetHeaderStr(name)
+		if value == "" {
+			return ""
+		}
+		return "header:" + name + ":" + value
+	}
+}
+
+// RateLimitMiddleware returns a middleware limiting requests with limiter,
+// keyed by RateLimitByIp unless WithRateLimitKey is set. It may be added to
+// routes, route groups or the whole server with AddMiddleware.
+//
+// Limited requests are answered with an error wrapping
+// transport.ErrTooManyRequests and a Retry-After header. All responses have
+// the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers of the IETF RateLimit header fields draft.
+// RateLimit-Limit is the number of requests allowed at once, which is the
+// burst of token buckets with ratelimit.WithBurst, also advertised as the
+// burst parameter of RateLimit-Policy.
+//
+// If the limiter fails, e.g. when its Redis store is unreachable, the error
+// is logged and the request is allowed.
+//
+// Example:
+//
+//	server.WithRouteMiddlewares(server.RateLimitMiddleware(
+//	    ratelimit.NewTokenBucket(10, time.Second, ratelimit.WithBurst(20)),
+//	    server.WithRateLimitKey(server.RateLimitByBearerToken()),
+//	))
+func RateLimitMiddleware(limiter ratelimit.Limiter, opts ...RateLimitOption) MiddlewareHandler {
+	rl := &rateLimit{
+		key:     RateLimitByIp(),
+		headers: true,
+	}
+
+	for _, opt := range opts {
+		opt(rl)
+	}
+
+	return func(next RequestHandler) RequestHandler {
+		return func(c *RequestContext) {
+			key := rl.key(c)
+			if key == "" {
+				next(c)
+				return
+			}
+
+			res, err := limiter.Allow(c.GetContext(), key)
+			if err != nil {
+				logger.ErrorContext(
+					c.GetContext(),
+					"rate limit",
+					log.Err(err),
+				)
+				next(c)
+				return
+			}
+
+			if res.Allowed {
+				next(c)
+			} else {
+				c.WriteError(
+					transport.NewError(
+						transport.ErrTooManyRequests,
+						"rate limit exceeded",
+						"RATE_LIMIT_EXCEEDED",
+					),
+				)
+				c.Response.Header.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			}
+
+			// Set after the handler, which may reset the response
+			if rl.headers {
+				policy := strconv.Itoa(res.Limit) + ";w=" + strconv.Itoa(ceilSeconds(res.Window))
+				if res.Burst != res.Limit {
+					policy += ";burst=" + strconv.Itoa(res.Burst)
+				}
+				c.Response.Header.Set("RateLimit-Limit", strconv.Itoa(res.Burst))
+				c.Response.Header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				c.Response.Header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+				c.Response.Header.Set("RateLimit-Policy", policy)
+			}
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			logger.Error(
+				"invalid trusted proxy",
+				slog.String("proxy", proxy),
+			)
+			continue
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes
+}
+
+// clientIp returns the client IP address of the request, see RateLimitByIp.
+func clientIp(c *RequestContext, trusted []netip.Prefix) string {
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+		if err != nil {
+			return false
+		}
+		return slices.ContainsFunc(trusted, func(prefix netip.Prefix) bool {
+			return prefix.Contains(addr.Unmap())
+		})
+	}
+
+	remote := c.RemoteIP().String()
+	if !isTrusted(remote) {
+		return remote
+	}
+
+	// Proxies may append an X-Forwarded-For header instead of extending
+	// the one of the client
+	var ips []string
+	for _, forwarded := range c.Request.Header.PeekAll("X-Forwarded-For") {
+		ips = append(ips, strings.Split(string(forwarded), ",")...)
+	}
+	if len(ips) > 0 {
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if !isTrusted(ip) {
+				return ip
+			}
+		}
+		return strings.TrimSpace(ips[0])
+	}
+
+	if ip := strings.TrimSpace(c.GetHeaderStr("X-Real-IP")); ip != "" {
+		return ip
+	}
+
+	return remote
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.microcore.dev/framework/ratelimit"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requestFrom serves a GET request from the remote address with h.
+// headers are name and value pairs.
+func requestFrom(h fasthttp.RequestHandler, remote string, headers ...string) *fasthttp.Response {
+	var req fasthttp.Request
+	req.SetRequestURI("/x")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP(remote), Port: 40000}, nil)
+	h(&ctx)
+	resp := &fasthttp.Response{}
+	ctx.Response.CopyTo(resp)
+	return resp
+}
+
+func TestClientIp(t *testing.T) {
+	trusted := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "invalid"})
+	if len(trusted) != 2 {
+		t.Fatalf("trusted proxies: got %v", trusted)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		remote  string
+		trusted bool
+		headers []string
+		want    string
+	}{
+		{"no proxy", "203.0.113.5", true, nil, "203.0.113.5"},
+		{"spoofed forwarded for", "203.0.113.5", true, []string{"X-Forwarded-For", "198.51.100.7"}, "203.0.113.5"},
+		{"spoofed real ip", "203.0.113.5", true, []string{"X-Real-IP", "198.51.100.7"}, "203.0.113.5"},
+		{"no trusted proxies", "10.0.0.1", false, []string{"X-Forwarded-For", "198.51.100.7"}, "10.0.0.1"},
+		{"trusted proxy", "10.0.0.1", true, []string{"X-Forwarded-For", "198.51.100.7"}, "198.51.100.7"},
+		{"proxy chain", "10.0.0.1", true, []string{"X-Forwarded-For", "1.2.3.4, 198.51.100.7, 192.168.1.1, 10.0.0.2"}, "198.51.100.7"},
+		{"repeated headers", "10.0.0.1", true, []string{"X-Forwarded-For", "1.2.3.4", "X-Forwarded-For", "198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"only proxies", "10.0.0.1", true, []string{"X-Forwarded-For", "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"real ip", "192.168.1.1", true, []string{"X-Real-IP", "198.51.100.9"}, "198.51.100.9"},
+		{"mapped address", "::ffff:10.0.0.1", true, []string{"X-Forwarded-For", "198.51.100.7"}, "198.51.100.7"},
+		{"proxy without headers", "10.0.0.1", true, nil, "10.0.0.1"},
+	} {
+		key := RateLimitByIp()
+		if tt.trusted {
+			key = RateLimitByIp("10.0.0.0/8", "192.168.1.1")
+		}
+		var got string
+		requestFrom(func(ctx *fasthttp.RequestCtx) {
+			got = key(&RequestContext{RequestCtx: ctx})
+		}, tt.remote, tt.headers...)
+		if got != "ip:"+tt.want {
+			t.Errorf("%s: got %s, want ip:%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.NewTokenBucket(1, time.Minute, ratelimit.WithBurst(2))
+	h := routeHandler(
+		WithRoutePath("/x"),
+		WithRouteMiddlewares(RateLimitMiddleware(limiter, WithRateLimitKey(RateLimitByIp("10.0.0.0/8")))),
+		WithRouteHandler(func(_ context.Context, c *RequestContext) {
+			c.WriteString("ok")
+		}),
+	)
+
+	for i := range 2 {
+		// Forged forwarding headers do not give a new quota
+		resp := requestFrom(h, "203.0.113.5", "X-Forwarded-For", "198.51.100."+strconv.Itoa(i+1))
+		if resp.StatusCode() != 200 || string(resp.Body()) != "ok" {
+			t.Fatalf("request %d: got %d %s", i, resp.StatusCode(), resp.Body())
+		}
+		for name, want := range map[string]string{
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": strconv.Itoa(1 - i),
+			"RateLimit-Policy":    "1;w=60;burst=2",
+		} {
+			if got := string(resp.Header.Peek(name)); got != want {
+				t.Errorf("request %d: got %s %q, want %q", i, name, got, want)
+			}
+		}
+	}
+
+	resp := requestFrom(h, "203.0.113.5")
+	if resp.StatusCode() != 429 || errResponse(t, resp).Code != "RATE_LIMIT_EXCEEDED" {
+		t.Fatalf("limited: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	if got := string(resp.Header.Peek("Retry-After")); got != "60" {
+		t.Errorf("limited: got Retry-After %q", got)
+	}
+	if got := string(resp.Header.Peek("RateLimit-Remaining")); got != "0" {
+		t.Errorf("limited: got RateLimit-Remaining %q", got)
+	}
+	if got := string(resp.Header.Peek("RateLimit-Reset")); got != "120" {
+		t.Errorf("limited: got RateLimit-Reset %q", got)
+	}
+
+	// Clients behind a trusted proxy have their own quota
+	if resp := requestFrom(h, "10.0.0.1", "X-Forwarded-For", "198.51.100.1"); resp.StatusCode() != 200 {
+		t.Errorf("trusted proxy: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	if resp := requestFrom(h, "203.0.113.6"); resp.StatusCode() != 200 {
+		t.Errorf("other client: got %d %s", resp.StatusCode(), resp.Body())
+	}
+}
@@ -3,18 +3,18 @@ package server // import "go.microcore.dev/framework/transport/http/server"
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"net"
 	"strconv"
 	"strings"
 
 	"github.com/valyala/fasthttp"
 	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
 	"go.microcore.dev/framework/transport"
 	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -24,8 +24,9 @@ type (
 	}
 
 	ErrResponse struct {
-		Message string `json:"message"`
-		Code    string `json:"code"`
+		Message string                 `json:"message"`
+		Code    string                 `json:"code"`
+		Details []transport.FieldError `json:"details,omitempty"`
 	}
 )
 
@@ -52,13 +53,39 @@ func (c *RequestContext) WriteStringWithStatusCode(statusCode http.StatusCode, s
 func (c *RequestContext) WriteError(err error) {
 	c.Response.Reset()
 
+	render := getErrorRenderer(c.RequestCtx)
+	statusCode, code := errorStatusCode(err)
+
 	var e transport.Error
 	if errors.As(err, &e) {
-		c.WriteJsonWithStatusCode(
-			http.ErrStatusCodeMap[e.Base],
+		render(
+			c,
+			statusCode,
 			ErrResponse{
 				Message: e.Error(),
-				Code:    e.GetCode(),
+				Code:    code,
+				Details: e.GetDetails(),
+			},
+		)
+		return
+	}
+
+	// Errors wrapping a base error such as transport.ErrNotFound. Only the
+	// base error is disclosed, the wrapped messages may hold internal details.
+	if base, ok := baseError(err); ok {
+		if statusCode >= http.StatusInternalServerError {
+			logger.ErrorContext(
+				c.GetContext(),
+				"internal",
+				log.Err(err),
+			)
+		}
+		render(
+			c,
+			statusCode,
+			ErrResponse{
+				Message: base.Error(),
+				Code:    code,
 			},
 		)
 		return
@@ -67,22 +94,46 @@ func (c *RequestContext) WriteError(err error) {
 	logger.ErrorContext(
 		c.GetContext(),
 		"internal",
-		slog.Any("error", err),
+		log.Err(err),
 	)
 
-	c.WriteJsonWithStatusCode(
-		http.ErrStatusCodeMap[defaultResponseErr],
+	render(
+		c,
+		statusCode,
 		ErrResponse{
 			Message: defaultResponseErr.Error(),
-			Code:    defaultResponseCode,
+			Code:    code,
 		},
 	)
 }
 
+// baseError returns the error of http.ErrStatusCodeMap that err wraps.
+func baseError(err error) (error, bool) {
+	for base := range http.ErrStatusCodeMap {
+		if errors.Is(err, base) {
+			return base, true
+		}
+	}
+	return nil, false
+}
+
+// baseErrorCode returns the ErrResponse code of a base error,
+// e.g. NOT_FOUND for transport.ErrNotFound.
+func baseErrorCode(base error) string {
+	return strings.ToUpper(strings.ReplaceAll(base.Error(), " ", "_"))
+}
+
+// WriteJson writes data encoded with the JSON codec of the server, see
+// WithCodecs.
 func (c *RequestContext) WriteJson(data any) error {
-	c.SetTraceIdHeader()
-	c.SetContentType("application/json; charset=utf-8")
-	return json.NewEncoder(c).Encode(data)
+	cd, _ := c.codecs().Get(codec.ContentTypeJson)
+	body, err := cd.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.SetContentType(cd.ContentType())
+	_, err = c.Write(body)
+	return err
 }
 
 func (c *RequestContext) WriteJsonWithStatusCode(statusCode http.StatusCode, data any) error {
@@ -99,8 +150,11 @@ func (c *RequestContext) StatusCode(code http.StatusCode) {
 	c.SetStatusCode(int(code))
 }
 
+// ReadJsonBody decodes the request body with the JSON codec of the server
+// regardless of its Content-Type. See ReadBody.
 func (c *RequestContext) ReadJsonBody(data any) error {
-	return json.Unmarshal(c.Request.Body(), data)
+	cd, _ := c.codecs().Get(codec.ContentTypeJson)
+	return cd.Unmarshal(c.Request.Body(), data)
 }
 
 func (c *RequestContext) UserValueBool(key any) (bool, error) {
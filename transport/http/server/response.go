@@ -0,0 +1,140 @@
+package server // import "go.microcore.dev/framework/transport/http/server"
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
+)
+
+// TypedHandler handles a request bound to Req and returns the response to
+// encode. It does not depend on the request context, so it can be unit
+// tested by calling it directly.
+type TypedHandler[Req, Resp any] func(ctx context.Context, req *Req) (*Resp, error)
+
+// WithRouteTypedHandler binds the request to Req with RequestContext.Bind,
+// calls handler and writes its result with RequestContext.WriteResponse.
+//
+// The response is written with the status code of WithRouteSuccessStatus,
+// or 200 OK. A nil response without error is written as 204 No Content
+// unless a success status is set. Errors are written with
+// RequestContext.WriteError, mapping them through http.ErrStatusCodeMap.
+//
+// Example:
+//
+//	server.WithRouteTypedHandler(func(ctx context.Context, req *GetUser) (*User, error) {
+//	    user, ok := users[req.ID]
+//	    if !ok {
+//	        return nil, transport.ErrNotFound
+//	    }
+//	    return user, nil
+//	})
+func WithRouteTypedHandler[Req, Resp any](handler TypedHandler[Req, Resp]) RouteOption {
+	return func(r *route) {
+		r.handler = func(c *RequestContext) {
+			var req Req
+			if err := c.Bind(&req); err != nil {
+				c.WriteError(err)
+				return
+			}
+
+			resp, err := handler(extractRequestContext(c.RequestCtx), &req)
+			if err != nil {
+				c.WriteError(err)
+				return
+			}
+
+			statusCode := r.status
+			if resp == nil {
+				if statusCode == 0 {
+					statusCode = http.StatusNoContent
+				}
+				c.StatusCode(statusCode)
+				return
+			}
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			if err := c.WriteResponse(statusCode, resp); err != nil {
+				c.WriteError(err)
+			}
+		}
+		r.doc.bind = reflect.TypeFor[Req]()
+		r.doc.result = reflect.TypeFor[Resp]()
+		r.doc.errors = append(r.doc.errors, bindErrors(r.doc.bind)...)
+		r.doc.errors = append(r.doc.errors, transport.ErrNotAcceptable)
+	}
+}
+
+// WriteResponse encodes data with the codec negotiated from the Accept
+// header of the request (the preferred codec if absent) and writes it with
+// statusCode. If no codec able to encode data is acceptable, it returns an
+// error wrapping transport.ErrNotAcceptable without writing anything.
+func (c *RequestContext) WriteResponse(statusCode http.StatusCode, data any) error {
+	codecs := c.codecs()
+
+	cd, ok := codecs.Negotiate(c.GetHeaderStr("Accept"), data)
+	if !ok {
+		return transport.NewError(
+			transport.ErrNotAcceptable,
+			"supported content types: "+strings.Join(codecs.ContentTypes(data), ", "),
+			"NOT_ACCEPTABLE",
+		)
+	}
+
+	body, err := cd.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	c.SetStatusCode(int(statusCode))
+	c.SetContentType(cd.ContentType())
+	_, err = c.Write(body)
+	return err
+}
+
+// ReadBody decodes the request body into v with the codec of its
+// Content-Type header (the preferred codec if absent).
+//
+// It returns an error wrapping transport.ErrUnsupportedMediaType if no codec
+// supports the content type or the body cannot be decoded.
+func (c *RequestContext) ReadBody(v any) error {
+	cd, err := c.requestCodec()
+	if err != nil {
+		return err
+	}
+	if err := cd.Unmarshal(c.Request.Body(), v); err != nil {
+		return transport.NewError(
+			transport.ErrUnsupportedMediaType,
+			"invalid "+cd.Name()+" body",
+			"INVALID_"+strings.ToUpper(cd.Name())+"_BODY",
+		)
+	}
+	return nil
+}
+
+// requestCodec returns the codec of the Content-Type header of the request.
+func (c *RequestContext) requestCodec() (codec.Codec, error) {
+	codecs := c.codecs()
+	cd, ok := codecs.Get(string(c.Request.Header.ContentType()))
+	if !ok {
+		return nil, transport.NewError(
+			transport.ErrUnsupportedMediaType,
+			"supported content types: "+strings.Join(codecs.ContentTypes(nil), ", "),
+			"UNSUPPORTED_MEDIA_TYPE",
+		)
+	}
+	return cd, nil
+}
+
+// codecs returns the codec registry of the server handling the request.
+func (c *RequestContext) codecs() *codec.Registry {
+	if r, ok := c.UserValue(codecsKey{}).(*codec.Registry); ok {
+		return r
+	}
+	return defaultCodecs
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.microcore.dev/framework/transport"
+	"go.microcore.dev/framework/transport/http/codec"
+)
+
+func TestBodyParserContentTypes(t *testing.T) {
+	type body struct {
+		A int `json:"a"`
+	}
+	h := routeHandler(
+		WithRouteMethod("POST"),
+		WithRoutePath("/x"),
+		WithRouteBodyParserHandler(func(_ context.Context, c *RequestContext, b *body) {
+			c.WriteString(strconv.Itoa(b.A))
+		}),
+	)
+
+	for contentType, status := range map[string]int{
+		"":                                  200,
+		"application/json":                  200,
+		"application/vnd.api+json":          200,
+		"application/x-www-form-urlencoded": 415,
+	} {
+		headers := []string{}
+		if contentType != "" {
+			headers = append(headers, "Content-Type", contentType)
+		}
+		resp := request(h, "POST", "/x", []byte(`{"a":1}`), headers...)
+		if resp.StatusCode() != status {
+			t.Errorf("%q: got %d %s", contentType, resp.StatusCode(), resp.Body())
+		}
+	}
+}
+
+func TestBodyParserValidation(t *testing.T) {
+	type body struct {
+		Phone string `json:"phone" validate:"required,e164"`
+	}
+	handler := WithRouteBodyParserHandler(func(_ context.Context, c *RequestContext, b *body) {
+		c.WriteString(b.Phone)
+	})
+
+	// Tags are not validated by default
+	h := routeHandler(WithRouteMethod("POST"), WithRoutePath("/x"), handler)
+	if resp := request(h, "POST", "/x", []byte(`{}`)); resp.StatusCode() != 200 {
+		t.Errorf("default: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	type validated struct {
+		Name string `json:"name" validate:"required"`
+	}
+	h = routeHandler(
+		WithRouteMethod("POST"),
+		WithRoutePath("/x"),
+		WithRouteBodyParserHandler(func(_ context.Context, c *RequestContext, b *validated) {
+			c.WriteString(b.Name)
+		}),
+		WithRouteBodyValidation(),
+	)
+	if resp := request(h, "POST", "/x", []byte(`{"name":"a"}`)); resp.StatusCode() != 200 {
+		t.Errorf("valid: got %d %s", resp.StatusCode(), resp.Body())
+	}
+	resp := request(h, "POST", "/x", []byte(`{}`))
+	if e := errResponse(t, resp); resp.StatusCode() != 400 || len(e.Details) != 1 || e.Details[0].Field != "name" {
+		t.Errorf("invalid: got %d %s", resp.StatusCode(), resp.Body())
+	}
+}
+
+type typedRequest struct {
+	ID int `path:"id"`
+}
+
+type typedResponse struct {
+	ID int `json:"id" xml:"id"`
+}
+
+func TestTypedHandler(t *testing.T) {
+	handler := func(_ context.Context, req *typedRequest) (*typedResponse, error) {
+		switch req.ID {
+		case 0:
+			return nil, nil
+		case 404:
+			return nil, transport.ErrNotFound
+		}
+		return &typedResponse{ID: req.ID}, nil
+	}
+	h := routeHandler(
+		WithRoutePath("/items/{id}"),
+		WithRouteTypedHandler(handler),
+	)
+
+	resp := request(h, "GET", "/items/7", nil)
+	if resp.StatusCode() != 200 || string(resp.Body()) != `{"id":7}` ||
+		codec.MediaType(string(resp.Header.ContentType())) != codec.ContentTypeJson {
+		t.Errorf("json: got %d %s %s", resp.StatusCode(), resp.Header.ContentType(), resp.Body())
+	}
+
+	resp = request(h, "GET", "/items/7", nil, "Accept", "application/xml")
+	if resp.StatusCode() != 200 || !strings.Contains(string(resp.Body()), "<id>7</id>") {
+		t.Errorf("xml: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	resp = request(h, "GET", "/items/7", nil, "Accept", "text/csv")
+	if resp.StatusCode() != 406 || errResponse(t, resp).Code != "NOT_ACCEPTABLE" {
+		t.Errorf("not acceptable: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	resp = request(h, "GET", "/items/0", nil)
+	if resp.StatusCode() != 204 || len(resp.Body()) != 0 {
+		t.Errorf("nil response: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	resp = request(h, "GET", "/items/404", nil)
+	if resp.StatusCode() != 404 || errResponse(t, resp).Code != "NOT_FOUND" {
+		t.Errorf("error: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	resp = request(h, "GET", "/items/x", nil)
+	if resp.StatusCode() != 400 {
+		t.Errorf("bind error: got %d %s", resp.StatusCode(), resp.Body())
+	}
+
+	// The success status applies to nil responses too
+	h = routeHandler(
+		WithRoutePath("/items/{id}"),
+		WithRouteTypedHandler(handler),
+		WithRouteSuccessStatus(202),
+	)
+	for _, uri := range []string{"/items/0", "/items/7"} {
+		if resp := request(h, "GET", uri, nil); resp.StatusCode() != 202 {
+			t.Errorf("%s success status: got %d", uri, resp.StatusCode())
+		}
+	}
+}
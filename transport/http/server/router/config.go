@@ -3,9 +3,12 @@ package router // import "go.microcore.dev/framework/transport/http/server/route
 import (
 	"log/slog"
 	"runtime/debug"
+	"sync/atomic"
+	"time"
 
 	"github.com/valyala/fasthttp"
 	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/log"
 )
 
 const (
@@ -16,14 +19,21 @@ const (
 	DefaultRouterRedirectFixedPath      = true
 	DefaultRouterHandleMethodNotAllowed = true
 	DefaultRouterHandleOPTIONS          = true
+
+	// Minimum interval between dumps of the crash buffer by the default
+	// panic handler, so that repeated panics do not flood its output.
+	DefaultRouterCrashDumpInterval = time.Minute
 )
 
+// Time of the last dump of the crash buffer, in Unix nanoseconds
+var lastCrashDump atomic.Int64
+
 func defaultRouterGlobalOPTIONS(ctx *fasthttp.RequestCtx) {
 }
 
 func defaultRouterNotFound(ctx *fasthttp.RequestCtx) {
 	ctx.Error(
-		fasthttp.StatusMessage(fasthttp.StatusNotFound), 
+		fasthttp.StatusMessage(fasthttp.StatusNotFound),
 		fasthttp.StatusNotFound,
 	)
 }
@@ -39,6 +49,16 @@ func defaultRouterPanicHandler(ctx *fasthttp.RequestCtx, r any) {
 		slog.Any("error", r),
 		slog.String("stack", string(debug.Stack())),
 	)
+	now := time.Now().UnixNano()
+	last := lastCrashDump.Load()
+	if now-last >= int64(DefaultRouterCrashDumpInterval) && lastCrashDump.CompareAndSwap(last, now) {
+		if err := log.DumpCrashBuffer(); err != nil {
+			logger.Error(
+				"failed to dump crash buffer",
+				log.Err(err),
+			)
+		}
+	}
 	ctx.Error(
 		fasthttp.StatusMessage(fasthttp.StatusInternalServerError),
 		fasthttp.StatusInternalServerError,
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.microcore.dev/framework/log"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestPanicHandlerCrashDump(t *testing.T) {
+	defer log.SetDefaultState()
+
+	var buf bytes.Buffer
+	log.EnableCrashBuffer(log.CrashBufferOptions{Writer: &buf})
+	lastCrashDump.Store(0)
+
+	for range 3 {
+		var ctx fasthttp.RequestCtx
+		defaultRouterPanicHandler(&ctx, "boom")
+		if ctx.Response.StatusCode() != fasthttp.StatusInternalServerError {
+			t.Fatalf("got status %d", ctx.Response.StatusCode())
+		}
+	}
+
+	// Repeated panics dump the crash buffer once per interval
+	if got := strings.Count(buf.String(), "--- last "); got != 1 {
+		t.Fatalf("expected 1 crash dump, got %d:\n%s", got, buf.String())
+	}
+}
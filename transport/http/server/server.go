@@ -1,19 +1,24 @@
 package server // import "go.microcore.dev/framework/transport/http/server"
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net"
+	"regexp"
 	"time"
 
 	_ "go.microcore.dev/framework"
 	"go.microcore.dev/framework/log"
 	"go.microcore.dev/framework/shutdown"
 	"go.microcore.dev/framework/telemetry"
+	"go.microcore.dev/framework/transport/http"
+	"go.microcore.dev/framework/transport/http/codec"
 	"go.microcore.dev/framework/transport/http/server/core"
 	"go.microcore.dev/framework/transport/http/server/listener"
+	"go.microcore.dev/framework/transport/http/server/openapi"
 	"go.microcore.dev/framework/transport/http/server/router"
 
 	fasthttpRouter "github.com/fasthttp/router"
@@ -36,8 +41,12 @@ type (
 		AddRoute(opts ...RouteOption) Manager
 		AddRouteGroup(opts ...RouteGroupOption) Manager
 		UseCors(opts ...CorsOption) Manager
+		UseCompression(opts ...CompressionOption) Manager
 		UseSwagger() Manager
+		UseOpenAPI(opts ...openapi.Option) Manager
+		GetOpenAPI(opts ...openapi.Option) *openapi.Document
 		UseProfiling() Manager
+		UseLogLevels() Manager
 		Listen() <-chan error
 		Up()
 		GetShutdownTimeout() time.Duration
@@ -49,9 +58,14 @@ type (
 		listener        net.Listener
 		core            *fasthttp.Server
 		router          *fasthttpRouter.Router
+		groupCors       *fasthttpRouter.Router
 		middleware      middleware
 		telemetry       telemetry.Manager
 		tls             *TLS
+		routes          []rawRoute
+		codecs          *codec.Registry
+		errorRenderer   ErrorRenderer
+		problemDetails  bool
 		shutdownTimeout time.Duration
 		shutdownHandler bool
 	}
@@ -61,23 +75,35 @@ type (
 		path        string
 		handler     func(*RequestContext)
 		middlewares []MiddlewareHandler
+		status      http.StatusCode
+		roles       []string
+		scopes      []string
+		validate    bool
+		doc         *routeDoc
 	}
 	rawRoute struct {
 		method  string
 		path    string
 		handler RequestHandler
+		doc     *routeDoc
 	}
 	routeGroup struct {
 		path        string
 		middlewares []MiddlewareHandler
 		rawRoutes   []rawRoute
 		routeGroups []*routeGroup
+		tags        []string
+		cors        *cors
 	}
 
 	cors struct {
-		origin  string
-		methods string
-		headers string
+		origins        []string
+		originPatterns []*regexp.Regexp
+		methods        string
+		headers        string
+		exposedHeaders string
+		credentials    bool
+		maxAge         time.Duration
 	}
 
 	TLS struct {
@@ -95,6 +121,7 @@ var logger = log.New(pkg)
 
 func New(opts ...Option) (Manager, error) {
 	server := &server{
+		codecs:          codec.NewRegistry(),
 		shutdownTimeout: DefaultShutdownTimeout,
 		shutdownHandler: DefaultShutdownHandler,
 	}
@@ -177,14 +204,25 @@ func (s *server) SetTelemetryManager(telemetry telemetry.Manager) Manager {
 					switch {
 					case statusCode >= 400:
 						var (
-							body    ErrResponse
+							// ErrResponse message or Problem detail
+							body struct {
+								Message string `json:"message"`
+								Detail  string `json:"detail"`
+							}
 							message string
 						)
-						if err := json.Unmarshal(c.Response.Body(), &body); err != nil {
+						// The body may be compressed by CompressionMiddleware
+						respBody, err := c.Response.BodyUncompressed()
+						if err != nil {
+							respBody = c.Response.Body()
+						} else {
+							err = json.Unmarshal(respBody, &body)
+						}
+						if err != nil {
 							span.RecordError(err)
-							message = string(c.Response.Body())
+							message = string(respBody)
 						} else {
-							message = body.Message
+							message = cmp.Or(body.Message, body.Detail)
 						}
 						span.SetStatus(codes.Error, message)
 					default:
@@ -205,7 +243,9 @@ func (s *server) EnableTLS(tls *TLS) Manager {
 }
 
 func (s *server) AddRoute(opts ...RouteOption) Manager {
-	applyRoute(s.router, newRawRoute(opts...))
+	route := newRawRoute(opts...)
+	applyRoute(s.router, route)
+	s.routes = append(s.routes, *route)
 	return s
 }
 
@@ -226,29 +266,48 @@ func (s *server) AddMiddleware(middleware MiddlewareHandler) Manager {
 }
 
 func (s *server) AddRouteGroup(opts ...RouteGroupOption) Manager {
-	applyRouteGroup(s.router, nil, newRouteGroup(opts...), []MiddlewareHandler{})
+	routeGroup := newRouteGroup(opts...)
+	applyRouteGroup(s.router, nil, routeGroup, []MiddlewareHandler{}, nil)
+	s.routes = append(s.routes, routeGroupRoutes("", routeGroup, nil)...)
+
+	if routes := routeGroupCorsRoutes("", routeGroup, false); len(routes) > 0 {
+		if s.groupCors == nil {
+			s.groupCors = fasthttpRouter.New()
+		}
+		registered := map[string]bool{}
+		for _, route := range routes {
+			if key := route.method + " " + route.path; !registered[key] {
+				registered[key] = true
+				s.groupCors.Handle(route.method, route.path, func(*fasthttp.RequestCtx) {})
+			}
+		}
+	}
+
 	return s
 }
 
+// UseCors handles Cross-Origin Resource Sharing for all routes, see
+// CorsMiddleware. Preflight requests are answered before routing.
+//
+// Routes of groups with WithRouteGroupCors, and their preflight requests,
+// are left to the policy of the group.
 func (s *server) UseCors(opts ...CorsOption) Manager {
-	cors := &cors{
-		origin:  DefaultCorsOrigin,
-		methods: DefaultCorsMethods,
-		headers: DefaultCorsHeaders,
-	}
-
-	for _, opt := range opts {
-		opt(cors)
-	}
+	cors := newCors(opts...)
 
 	s.middleware = append(
 		s.middleware,
 		func(handler fasthttp.RequestHandler) fasthttp.RequestHandler {
+			h := cors.middleware(func(c *RequestContext) {
+				handler(c.RequestCtx)
+			})
 			return func(ctx *fasthttp.RequestCtx) {
-				handler(ctx)
-				ctx.Response.Header.Set("Access-Control-Allow-Origin", cors.origin)
-				ctx.Response.Header.Set("Access-Control-Allow-Methods", cors.methods)
-				ctx.Response.Header.Set("Access-Control-Allow-Headers", cors.headers)
+				if s.groupCors != nil {
+					if g, _ := s.groupCors.Lookup(string(ctx.Method()), string(ctx.Path()), nil); g != nil {
+						handler(ctx)
+						return
+					}
+				}
+				h(&RequestContext{RequestCtx: ctx})
 			}
 		},
 	)
@@ -268,6 +327,20 @@ func (s *server) UseProfiling() Manager {
 	return s
 }
 
+// UseLogLevels mounts an admin endpoint at /debug/log/levels to inspect
+// (GET) and change (PUT, see LogLevelsRequest) the global and per-package
+// log levels at runtime. Like UseProfiling, it should only be reachable
+// from trusted networks.
+func (s *server) UseLogLevels() Manager {
+	s.router.Handle(http.MethodGet, "/debug/log/levels", func(ctx *fasthttp.RequestCtx) {
+		getLogLevels(&RequestContext{RequestCtx: ctx})
+	})
+	s.router.Handle(http.MethodPut, "/debug/log/levels", func(ctx *fasthttp.RequestCtx) {
+		putLogLevels(&RequestContext{RequestCtx: ctx})
+	})
+	return s
+}
+
 func (s *server) Listen() <-chan error {
 	exit := make(chan error, 1)
 	go func() {
@@ -275,10 +348,10 @@ func (s *server) Listen() <-chan error {
 			s.middleware,
 			func(handler fasthttp.RequestHandler) fasthttp.RequestHandler {
 				return func(c *fasthttp.RequestCtx) {
+					// The telemetry middleware has already extracted the
+					// remote context and started the server span, so the
+					// request log is correlated with the server span.
 					ctx := extractRequestContext(c)
-					if s.telemetry != nil {
-						ctx = s.telemetry.GetPropagator().Extract(ctx, fasthttpRequestCtxHeaderCarrier{c})
-					}
 					defer func() {
 						logger.LogAttrs(
 							ctx,
@@ -298,6 +371,14 @@ func (s *server) Listen() <-chan error {
 		for i := len(s.middleware) - 1; i >= 0; i-- {
 			handler = s.middleware[i](handler)
 		}
+		next := handler
+		handler = func(c *fasthttp.RequestCtx) {
+			c.SetUserValue(codecsKey{}, s.codecs)
+			if s.errorRenderer != nil {
+				c.SetUserValue(errorRendererKey{}, s.errorRenderer)
+			}
+			next(c)
+		}
 		s.core.Handler = handler
 
 		addr := s.listener.Addr().(*net.TCPAddr)
@@ -336,7 +417,7 @@ func (s *server) Up() {
 	if err := <-s.Listen(); err != nil {
 		logger.Error(
 			"failed to listen",
-			slog.Any("error", err),
+			log.Err(err),
 		)
 	}
 	logger.Info("stopped")
@@ -368,13 +449,15 @@ func newRawRoute(opts ...RouteOption) *rawRoute {
 		path:        DefaultRoutePath,
 		handler:     defaultRouteHandler,
 		middlewares: []MiddlewareHandler{},
+		doc:         &routeDoc{},
 	}
 
 	for _, opt := range opts {
 		opt(route)
 	}
+	route.doc.status = route.status
 
-	handler := RequestHandler(route.handler)
+	handler := authorize(route, route.handler)
 	for i := len(route.middlewares) - 1; i >= 0; i-- {
 		handler = route.middlewares[i](handler)
 	}
@@ -383,6 +466,7 @@ func newRawRoute(opts ...RouteOption) *rawRoute {
 		method:  route.method,
 		path:    route.path,
 		handler: handler,
+		doc:     route.doc,
 	}
 }
 
@@ -415,19 +499,29 @@ func newRouteGroup(opts ...RouteGroupOption) *routeGroup {
 	return routeGroup
 }
 
-func applyRouteGroup(router *fasthttpRouter.Router, group *fasthttpRouter.Group, routeGroup *routeGroup, middlewares []MiddlewareHandler) {
+func applyRouteGroup(router *fasthttpRouter.Router, group *fasthttpRouter.Group, routeGroup *routeGroup, middlewares []MiddlewareHandler, cors *cors) {
 	if group == nil {
 		group = router.Group(routeGroup.path)
 	} else {
 		group = group.Group(routeGroup.path)
 	}
 
+	// The CORS handling of a nested group replaces the inherited one
+	if routeGroup.cors != nil {
+		cors = routeGroup.cors
+	}
+
 	routeGroup.middlewares = append(middlewares, routeGroup.middlewares...)
 
 	for _, rawRoute := range routeGroup.rawRoutes {
 		for i := len(routeGroup.middlewares) - 1; i >= 0; i-- {
 			rawRoute.handler = routeGroup.middlewares[i](rawRoute.handler)
 		}
+		// CORS handling wraps the middlewares, so that their errors have
+		// CORS headers too
+		if cors != nil {
+			rawRoute.handler = cors.middleware(rawRoute.handler)
+		}
 		group.Handle(
 			rawRoute.method,
 			rawRoute.path,
@@ -441,8 +535,45 @@ func applyRouteGroup(router *fasthttpRouter.Router, group *fasthttpRouter.Group,
 		)
 	}
 
+	if cors != nil {
+		applyRouteGroupPreflight(group, routeGroup, cors)
+	}
+
 	for _, g := range routeGroup.routeGroups {
-		applyRouteGroup(router, group, g, routeGroup.middlewares)
+		applyRouteGroup(router, group, g, routeGroup.middlewares, cors)
+	}
+}
+
+// applyRouteGroupPreflight registers OPTIONS routes answering the preflight
+// requests of the group routes, which the router would otherwise answer
+// itself. Paths with an OPTIONS route of their own are left as is.
+func applyRouteGroupPreflight(group *fasthttpRouter.Group, routeGroup *routeGroup, cors *cors) {
+	paths := map[string]bool{}
+	for _, rawRoute := range routeGroup.rawRoutes {
+		if rawRoute.method == http.MethodOptions {
+			paths[rawRoute.path] = true
+		}
+	}
+
+	handler := cors.middleware(func(c *RequestContext) {
+		c.StatusCode(http.StatusNoContent)
+	})
+
+	for _, rawRoute := range routeGroup.rawRoutes {
+		if paths[rawRoute.path] {
+			continue
+		}
+		paths[rawRoute.path] = true
+		group.OPTIONS(
+			rawRoute.path,
+			func(ctx *fasthttp.RequestCtx) {
+				handler(
+					&RequestContext{
+						RequestCtx: ctx,
+					},
+				)
+			},
+		)
 	}
 }
 
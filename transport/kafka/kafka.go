@@ -224,7 +224,7 @@ func (k *k) Sub(topic string, opts ...SubOption) error {
 				}
 				logger.Error(
 					"sub: failed to read message",
-					slog.Any("error", err),
+					log.Err(err),
 					slog.String("topic", topic),
 				)
 				continue
@@ -238,7 +238,7 @@ func (k *k) Sub(topic string, opts ...SubOption) error {
 			if err := sub.handler(wctx, msg); err != nil {
 				logger.Error(
 					"sub: message handler failed",
-					slog.Any("error", err),
+					log.Err(err),
 					slog.String("topic", topic),
 				)
 				if k.telemetry != nil {
@@ -325,6 +325,14 @@ func (f headerCarrier) Get(key string) string {
 }
 
 func (f headerCarrier) Set(key, value string) {
+	// Replace the existing header so that a message forwarded between
+	// services carries a single traceparent/baggage value.
+	for i, v := range *f.headers {
+		if v.Key == key {
+			(*f.headers)[i].Value = []byte(value)
+			return
+		}
+	}
 	*f.headers = append(
 		*f.headers,
 		kafka.Header{
@@ -0,0 +1,25 @@
+package kafka
+
+import (
+	"testing"
+
+	"go.microcore.dev/framework/telemetry/telemetrytest"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestPropagation(t *testing.T) {
+	telemetrytest.AssertPropagation(t, func(inject func(propagation.TextMapCarrier)) propagation.TextMapCarrier {
+		headers := []kafka.Header{}
+		inject(headerCarrier{&headers})
+
+		// Injecting twice replaces the headers
+		n := len(headers)
+		inject(headerCarrier{&headers})
+		if len(headers) != n {
+			t.Errorf("got %d headers after injecting twice, want %d", len(headers), n)
+		}
+		return headerCarrier{&headers}
+	})
+}
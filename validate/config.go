@@ -0,0 +1,15 @@
+package validate // import "go.microcore.dev/framework/validate"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+const (
+	pkg = "go.microcore.dev/framework/validate"
+
+	DefaultTagName = "validate"
+	DefaultNameTag = "json"
+
+	// Code is the code of validation errors.
+	Code = "VALIDATION_FAILED"
+)
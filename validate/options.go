@@ -0,0 +1,24 @@
+package validate // import "go.microcore.dev/framework/validate"
+
+import (
+	_ "go.microcore.dev/framework"
+)
+
+type Option func(*validator)
+
+// WithTagName sets the struct tag holding the rules.
+// Defaults to DefaultTagName.
+func WithTagName(tag string) Option {
+	return func(v *validator) {
+		v.tag = tag
+	}
+}
+
+// WithNameTags sets the struct tags field names are read from, in order of
+// precedence. Fields without any of them are named after the Go field.
+// Defaults to DefaultNameTag.
+func WithNameTags(tags ...string) Option {
+	return func(v *validator) {
+		v.nameTags = tags
+	}
+}
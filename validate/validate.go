@@ -0,0 +1,413 @@
+package validate // import "go.microcore.dev/framework/validate"
+
+/*
+Package validate checks struct fields against rules declared in struct tags
+and reports every offending field.
+
+Rules are separated by commas and applied in order; the first failing rule
+of a field is reported:
+
+	type CreateUser struct {
+	    Name    string   `json:"name" validate:"required,min=2,max=64"`
+	    Email   string   `json:"email" validate:"required,email"`
+	    Role    string   `json:"role" validate:"oneof=admin user"`
+	    Tags    []string `json:"tags" validate:"max=10,dive,min=1"`
+	    Code    string   `json:"code" validate:"omitempty,regex=^[A-Z]{3}$"`
+	    Address *Address `json:"address" validate:"required"`
+	}
+
+Supported rules:
+
+  - required: the value is not the zero value (nil, empty string or collection).
+  - omitempty: skip the remaining rules if the value is the zero value.
+  - min=N, max=N, len=N, gt=N, gte=N, lt=N, lte=N: bounds of numbers, of
+    the number of characters of strings, or of the length of slices and maps.
+  - email: an email address without display name.
+  - uuid: a UUID in canonical form.
+  - oneof=A B C: one of the space separated values.
+  - regex=PATTERN: matches PATTERN. It must be the last rule, so the
+    pattern may contain commas.
+  - dive: apply the remaining rules to every element of a slice, array or map.
+
+Nested structs, pointers to structs and collections of them are validated
+recursively. Field paths in errors use the json names, e.g. "items[0].name".
+
+Struct returns a transport.Error with transport.ErrBadRequest as base, Code
+as code and a transport.FieldError per offending field, which transports
+serialize in their error responses.
+*/
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	_ "go.microcore.dev/framework"
+	"go.microcore.dev/framework/transport"
+)
+
+type (
+	validator struct {
+		tag      string
+		nameTags []string
+		details  []transport.FieldError
+	}
+
+	rule struct {
+		name  string
+		param string
+	}
+)
+
+var (
+	rulesCache   sync.Map
+	regexpsCache sync.Map
+
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Struct validates the struct (or pointer to struct) v.
+//
+// It returns nil if all rules pass, a transport.Error with the offending
+// fields as details otherwise, or a plain error if a tag is invalid.
+func Struct(v any, opts ...Option) error {
+	val := &validator{
+		tag:      DefaultTagName,
+		nameTags: []string{DefaultNameTag},
+	}
+
+	for _, opt := range opts {
+		opt(val)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected struct, got %T", v)
+	}
+
+	if err := val.fields(rv, ""); err != nil {
+		return err
+	}
+
+	if len(val.details) == 0 {
+		return nil
+	}
+
+	return transport.NewErrorWithDetails(
+		transport.ErrBadRequest,
+		"validation failed",
+		Code,
+		val.details,
+	)
+}
+
+func (val *validator) fields(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		tag := f.Tag.Get(val.tag)
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			if err := val.fields(v.Field(i), prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		rules, err := parseRules(tag)
+		if err != nil {
+			return fmt.Errorf("validate: field %s.%s: %w", t.Name(), f.Name, err)
+		}
+
+		path := val.fieldName(f)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if err := val.value(v.Field(i), path, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (val *validator) fieldName(f reflect.StructField) string {
+	for _, tag := range val.nameTags {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// value applies rules to v and validates nested structs.
+func (val *validator) value(v reflect.Value, path string, rules []rule) error {
+	for i, r := range rules {
+		switch r.name {
+		case "omitempty":
+			if v.IsZero() {
+				return nil
+			}
+			continue
+		case "dive":
+			return val.dive(v, path, rules[i+1:])
+		}
+
+		message, err := check(v, r)
+		if err != nil {
+			return fmt.Errorf("validate: field %s: %w", path, err)
+		}
+		if message != "" {
+			val.details = append(val.details, transport.FieldError{
+				Field:   path,
+				Rule:    r.name,
+				Message: message,
+			})
+			return nil
+		}
+	}
+
+	return val.nested(v, path)
+}
+
+func (val *validator) dive(v reflect.Value, path string, rules []rule) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := val.value(v.Index(i), path+"["+strconv.Itoa(i)+"]", rules); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := val.value(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", rules); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("validate: field %s: dive on %s", path, v.Type())
+	}
+	return nil
+}
+
+// nested validates structs, pointers to structs and collections of them.
+func (val *validator) nested(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return val.fields(v, path)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := v.Type().Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct {
+			return val.dive(v, path, nil)
+		}
+	}
+	return nil
+}
+
+// check returns the message describing why v fails r, or "" if it passes.
+func check(v reflect.Value, r rule) (string, error) {
+	if r.name == "required" {
+		if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+			return "is required", nil
+		}
+		return "", nil
+	}
+
+	// Other rules do not apply to missing values
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch r.name {
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		return checkBound(v, r)
+	case "email":
+		s, err := stringValue(v, r)
+		if err != nil {
+			return "", err
+		}
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address", nil
+		}
+	case "uuid":
+		s, err := stringValue(v, r)
+		if err != nil {
+			return "", err
+		}
+		if !uuidRegexp.MatchString(s) {
+			return "must be a valid UUID", nil
+		}
+	case "oneof":
+		values := strings.Fields(r.param)
+		s := fmt.Sprint(v.Interface())
+		for _, value := range values {
+			if s == value {
+				return "", nil
+			}
+		}
+		return "must be one of: " + strings.Join(values, ", "), nil
+	case "regex":
+		s, err := stringValue(v, r)
+		if err != nil {
+			return "", err
+		}
+		re, err := compileRegexp(r.param)
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(s) {
+			return "must match pattern " + r.param, nil
+		}
+	default:
+		return "", fmt.Errorf("unknown rule %q", r.name)
+	}
+
+	return "", nil
+}
+
+func checkBound(v reflect.Value, r rule) (string, error) {
+	bound, err := strconv.ParseFloat(r.param, 64)
+	if err != nil {
+		return "", fmt.Errorf("rule %s: invalid number %q", r.name, r.param)
+	}
+
+	var (
+		n    float64
+		unit string
+	)
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return "", fmt.Errorf("rule %s: unsupported type %s", r.name, v.Type())
+	}
+
+	var (
+		ok     bool
+		format string
+	)
+	switch r.name {
+	case "min", "gte":
+		ok, format = n >= bound, "must be at least %s%s"
+	case "max", "lte":
+		ok, format = n <= bound, "must be at most %s%s"
+	case "len":
+		ok, format = n == bound, "must be exactly %s%s"
+	case "gt":
+		ok, format = n > bound, "must be greater than %s%s"
+	case "lt":
+		ok, format = n < bound, "must be less than %s%s"
+	}
+	if ok {
+		return "", nil
+	}
+	if unit == " characters" && r.name == "len" {
+		return fmt.Sprintf("must be exactly %s characters long", r.param), nil
+	}
+	return fmt.Sprintf(format, r.param, unit), nil
+}
+
+func stringValue(v reflect.Value, r rule) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("rule %s: unsupported type %s", r.name, v.Type())
+	}
+	return v.String(), nil
+}
+
+// parseRules parses and caches the rules of a tag.
+func parseRules(tag string) ([]rule, error) {
+	if rules, ok := rulesCache.Load(tag); ok {
+		return rules.([]rule), nil
+	}
+
+	rules := []rule{}
+	for rest := tag; rest != ""; {
+		var s string
+		if strings.HasPrefix(strings.TrimSpace(rest), "regex=") {
+			// The pattern extends to the end of the tag
+			s, rest = strings.TrimSpace(rest), ""
+		} else {
+			s, rest, _ = strings.Cut(rest, ",")
+			s = strings.TrimSpace(s)
+		}
+		if s == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(s, "=")
+		switch name {
+		case "required", "omitempty", "dive", "email", "uuid":
+		case "min", "max", "len", "gt", "gte", "lt", "lte", "oneof":
+			if param == "" {
+				return nil, fmt.Errorf("rule %s requires a parameter", name)
+			}
+		case "regex":
+			if _, err := compileRegexp(param); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, rule{name: name, param: param})
+	}
+
+	rulesCache.Store(tag, rules)
+	return rules, nil
+}
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpsCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("rule regex: %w", err)
+	}
+	regexpsCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,89 @@
+package validate
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"go.microcore.dev/framework/transport"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=5"`
+}
+
+type user struct {
+	Name    string            `json:"name" validate:"required,min=2,max=5"`
+	Email   string            `json:"email" validate:"email"`
+	ID      string            `json:"id" validate:"omitempty,uuid"`
+	Role    string            `json:"role" validate:"oneof=admin user"`
+	Age     int               `json:"age" validate:"gte=18,lt=150"`
+	Code    string            `json:"code" validate:"omitempty,regex=^[A-Z]{1,3}$"`
+	Tags    []string          `json:"tags" validate:"max=2,dive,min=2"`
+	Labels  map[string]string `json:"labels" validate:"dive,required"`
+	Address *address          `json:"address" validate:"required"`
+	Others  []address         `json:"others"`
+	private string            `validate:"required"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := user{
+		Name:    "bob",
+		Email:   "bob@example.com",
+		ID:      "123e4567-e89b-12d3-a456-426614174000",
+		Role:    "admin",
+		Age:     30,
+		Code:    "AB",
+		Tags:    []string{"ab"},
+		Address: &address{City: "Oslo"},
+	}
+	if err := Struct(&valid); err != nil {
+		t.Fatalf("expected valid struct, got %v", err)
+	}
+
+	err := Struct(user{
+		Name:    "b",
+		Email:   "Bob <bob@example.com>",
+		ID:      "nope",
+		Role:    "root",
+		Age:     12,
+		Code:    "abcd",
+		Tags:    []string{"ok", "x"},
+		Labels:  map[string]string{"k": ""},
+		Address: &address{Zip: "123"},
+		Others:  []address{{City: "Rome"}, {}},
+	})
+
+	var e transport.Error
+	if !errors.As(err, &e) || !errors.Is(err, transport.ErrBadRequest) || e.GetCode() != Code {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	got := []string{}
+	for _, d := range e.GetDetails() {
+		got = append(got, d.Field+":"+d.Rule)
+	}
+	want := []string{
+		"name:min", "email:email", "id:uuid", "role:oneof", "age:gte", "code:regex",
+		"tags[1]:min", "labels[k]:required", "address.city:required", "address.zip:len",
+		"others[1].city:required",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected details:\n got %v\nwant %v", got, want)
+	}
+
+	if err := Struct(user{Tags: []string{"a", "b", "c"}}, WithNameTags("none")); err == nil ||
+		!slices.ContainsFunc(err.(transport.Error).GetDetails(), func(d transport.FieldError) bool {
+			return d.Field == "Tags" && d.Rule == "max" && d.Message == "must be at most 2 items"
+		}) {
+		t.Fatalf("expected Go field names, got %v", err)
+	}
+
+	type invalid struct {
+		N int `validate:"unknown"`
+	}
+	if err := Struct(invalid{}); err == nil || errors.Is(err, transport.ErrBadRequest) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+}